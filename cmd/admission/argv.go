@@ -0,0 +1,110 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/config"
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/parser"
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/utils"
+	"github.com/lmittmann/tint"
+	"github.com/pborman/getopt/v2"
+)
+
+type Opts struct {
+	dataDir  string
+	isTmpDir bool
+	config   config.Config
+	minYears int
+
+	// query, optional
+	school   string
+	course   string
+	location string
+	score    *float32
+}
+
+func ParseOpts() Opts {
+	tmpDir, _ := utils.TmpDirectory() // we don't care if err
+
+	// definition
+	help := getopt.BoolLong("help", 'h', "Shows the help menu")
+	configPath := getopt.StringLong("config", 'c', "", "Path of the config file (yaml or toml). Defaults to RANKINGS_CONFIG env, if set")
+	dataDir := getopt.StringLong("data-dir", 'd', tmpDir, "Path of the data folder (containing html, json, ...). Defaults to tmp directory")
+
+	minYears := getopt.IntLong("min-years", 'm', parser.DefaultAdmissionMinYears, "Courses with less years of history are flagged as insufficient")
+	school := getopt.StringLong("school", 's', "", "Query: school of the course")
+	course := getopt.StringLong("course", 0, "", "Query: course title, or part of it (case insensitive)")
+	location := getopt.StringLong("location", 'l', "", "Query: course location (optional)")
+	score := getopt.StringLong("score", 0, "", "Query: result to estimate the admission probability of")
+
+	// parsing
+	getopt.Parse()
+
+	if *help {
+		getopt.Usage()
+		os.Exit(0)
+	}
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		slog.Error("Could not load config.", "error", err)
+		os.Exit(2)
+	}
+
+	// flags override config file and env
+	if getopt.IsSet("data-dir") || cfg.DataDir == "" {
+		cfg.DataDir = *dataDir
+	}
+
+	absDataDir, err := filepath.Abs(cfg.DataDir)
+	if err != nil {
+		tint.Err(err)
+		os.Exit(1)
+	}
+	cfg.DataDir = absDataDir
+
+	dataDirExists, err := utils.DoFolderExists(absDataDir)
+	if !dataDirExists {
+		slog.Error("You must set the --data-dir flag to an existing directory.")
+		os.Exit(2)
+	}
+	if err != nil {
+		tint.Err(err)
+		os.Exit(1)
+	}
+
+	if *minYears < 1 {
+		slog.Error("--min-years must be at least 1.", "min_years", *minYears)
+		os.Exit(2)
+	}
+
+	opts := Opts{
+		dataDir:  absDataDir,
+		isTmpDir: absDataDir == tmpDir,
+		config:   cfg,
+		minYears: *minYears,
+		school:   *school,
+		course:   *course,
+		location: *location,
+	}
+
+	if *score != "" {
+		if *school == "" || *course == "" {
+			slog.Error("--score requires --school and --course.")
+			os.Exit(2)
+		}
+
+		value, err := strconv.ParseFloat(*score, 32)
+		if err != nil {
+			slog.Error("Invalid --score.", "score", *score, "error", err)
+			os.Exit(2)
+		}
+		v := float32(value)
+		opts.score = &v
+	}
+
+	return opts
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/constants"
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/logger"
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/parser"
+)
+
+func main() {
+	slog.SetDefault(logger.GetDefaultLogger())
+	opts := ParseOpts()
+	slog.SetDefault(logger.NewLogger(opts.config.Log))
+
+	outputDir := path.Join(opts.dataDir, constants.OutputBaseFolder) // abs path
+	admissionOutDir := path.Join(outputDir, constants.OutputAdmissionFolder)
+	slog.Info("argv validation", "data_dir", opts.dataDir, "min_years", opts.minYears)
+
+	rankings, err := parser.LoadRankings(outputDir)
+	if err != nil {
+		slog.Error("could not load parsed rankings", "error", err)
+		os.Exit(1)
+	}
+
+	models := parser.NewAdmissionModels(rankings, opts.minYears)
+	for _, m := range models {
+		insufficient := 0
+		for _, c := range m.Courses {
+			if c.InsufficientHistory {
+				insufficient++
+			}
+		}
+		slog.Info("[admission] model fitted", "school", m.School, "years", m.Years, "courses", len(m.Courses), "insufficient_history", insufficient)
+	}
+
+	if err := parser.WriteAdmissionModels(admissionOutDir, models, opts.config.Output.IndentIndexes); err != nil {
+		slog.Error("could not write admission models", "error", err)
+		os.Exit(1)
+	}
+	slog.Info("[admission] successful write", "files", len(models))
+
+	if opts.score != nil {
+		query(models, opts)
+	}
+}
+
+func query(models []*parser.AdmissionModel, opts Opts) {
+	found := false
+	for _, m := range models {
+		if !strings.EqualFold(m.School, opts.school) {
+			continue
+		}
+
+		for _, c := range m.FindCourses(opts.course, opts.location) {
+			found = true
+			for _, p := range c.Phases {
+				attrs := []any{"course", c.Title, "location", c.Location, "phase", p.Label, "language", p.Language, "extra_eu", p.IsExtraEu,
+					"score", *opts.score, "probability", fmt.Sprintf("%.1f%%", p.Probability(*opts.score)*100), "years", len(p.Samples)}
+				if p.InsufficientHistory {
+					slog.Warn("[admission] estimate (insufficient history, not reliable)", attrs...)
+				} else {
+					slog.Info("[admission] estimate", attrs...)
+				}
+			}
+		}
+	}
+
+	if !found {
+		slog.Error("[admission] no course matches the query", "school", opts.school, "course", opts.course, "location", opts.location)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+
+	"github.com/pborman/getopt/v2"
+)
+
+type Opts struct {
+	action     string
+	configPath string
+	dataDir    string
+}
+
+var actions = []string{"print"}
+
+func ParseOpts() Opts {
+	// definition
+	help := getopt.BoolLong("help", 'h', "Shows the help menu")
+	configPath := getopt.StringLong("config", 'c', "", "Path of the config file (yaml or toml). Defaults to RANKINGS_CONFIG env, if set")
+	dataDir := getopt.StringLong("data-dir", 'd', "", "Path of the data folder, overrides the config file and env")
+	getopt.SetParameters("print")
+
+	// parsing
+	getopt.Parse()
+
+	// allow options after the action too (e.g. `config print -c file.yaml`)
+	action := getopt.Arg(0)
+	if getopt.NArgs() > 1 {
+		getopt.CommandLine.Parse(append([]string{os.Args[0]}, getopt.Args()[1:]...))
+	}
+
+	if *help {
+		getopt.Usage()
+		os.Exit(0)
+	}
+
+	if action != "print" || getopt.NArgs() > 1 {
+		slog.Error("You must specify an action.", "actions", actions)
+		getopt.Usage()
+		os.Exit(2)
+	}
+
+	return Opts{
+		action:     action,
+		configPath: *configPath,
+		dataDir:    *dataDir,
+	}
+}
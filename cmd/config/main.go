@@ -0,0 +1,35 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/config"
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/logger"
+)
+
+func main() {
+	slog.SetDefault(logger.GetDefaultLogger())
+	opts := ParseOpts()
+
+	cfg, err := config.Load(opts.configPath)
+	if err != nil {
+		slog.Error("Could not load config.", "error", err)
+		os.Exit(2)
+	}
+
+	if opts.dataDir != "" {
+		cfg.DataDir = opts.dataDir
+	}
+
+	switch opts.action {
+	case "print":
+		out, err := cfg.Redacted().ToYaml()
+		if err != nil {
+			panic(err)
+		}
+
+		fmt.Print(string(out))
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+	"path"
+
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/constants"
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/logger"
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/parser"
+)
+
+func main() {
+	slog.SetDefault(logger.GetDefaultLogger())
+	opts := ParseOpts()
+	slog.SetDefault(logger.NewLogger(opts.config.Log))
+
+	outputDir := path.Join(opts.dataDir, constants.OutputBaseFolder) // abs path
+	slog.Info("argv validation", "data_dir", opts.dataDir, "from", opts.from, "to", opts.to)
+
+	var diffs []*parser.RankingDiff
+	if opts.from != "" {
+		diff, err := diffPair(outputDir, opts.from, opts.to)
+		if err != nil {
+			slog.Error("could not diff rankings", "from", opts.from, "to", opts.to, "error", err)
+			os.Exit(1)
+		}
+		diffs = []*parser.RankingDiff{diff}
+	} else {
+		rankings, err := parser.LoadRankings(outputDir)
+		if err != nil {
+			slog.Error("could not load parsed rankings", "error", err)
+			os.Exit(1)
+		}
+		diffs = parser.DiffConsecutivePhases(rankings)
+	}
+
+	for _, d := range diffs {
+		slog.Info("[diff] rankings compared", "from", d.From.Id, "to", d.To.Id, "students", len(d.Students), "courses", len(d.Courses), "skipped_rows", d.SkippedRows)
+	}
+
+	if err := parser.WriteDiffs(outputDir, diffs, opts.config.Output.IndentIndexes); err != nil {
+		slog.Error("could not write diffs", "error", err)
+		os.Exit(1)
+	}
+
+	slog.Info("[diff] successful write", "diffs", len(diffs))
+}
+
+func diffPair(outputDir, fromId, toId string) (*parser.RankingDiff, error) {
+	rankingsDir := path.Join(outputDir, constants.OutputParsedRankingsFolder)
+	from, err := parser.LoadRanking(path.Join(rankingsDir, fromId+".json"))
+	if err != nil {
+		return nil, err
+	}
+
+	to, err := parser.LoadRanking(path.Join(rankingsDir, toId+".json"))
+	if err != nil {
+		return nil, err
+	}
+
+	return parser.DiffRankings(&from, &to)
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/config"
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/parser"
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/utils"
+	"github.com/lmittmann/tint"
+	"github.com/pborman/getopt/v2"
+)
+
+type Opts struct {
+	dataDir    string
+	isTmpDir   bool
+	from       string // sources, see parser.LoadManifestiSource
+	to         string
+	reportPath string
+	config     config.Config
+}
+
+func ParseOpts() Opts {
+	tmpDir, _ := utils.TmpDirectory() // we don't care if err
+
+	// definition
+	help := getopt.BoolLong("help", 'h', "Shows the help menu")
+	configPath := getopt.StringLong("config", 'c', "", "Path of the config file (yaml or toml). Defaults to RANKINGS_CONFIG env, if set")
+	dataDir := getopt.StringLong("data-dir", 'd', tmpDir, "Path of the data folder (containing html, json, ...). Defaults to tmp directory")
+	from := getopt.StringLong("from", 0, parser.ManifestiSourceRemote, "Old manifesti: remote, local, git:<revision> (of the data folder) or the path of a JSON file")
+	to := getopt.StringLong("to", 0, parser.ManifestiSourceLocal, "New manifesti, same values of --from")
+	reportPath := getopt.StringLong("report", 'r', "", "Path of the JSON file where the diff is written. If not set, it is only logged")
+
+	// parsing
+	getopt.Parse()
+
+	if *help {
+		getopt.Usage()
+		os.Exit(0)
+	}
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		slog.Error("Could not load config.", "error", err)
+		os.Exit(2)
+	}
+
+	// flags override config file and env
+	if getopt.IsSet("data-dir") || cfg.DataDir == "" {
+		cfg.DataDir = *dataDir
+	}
+
+	absDataDir, err := filepath.Abs(cfg.DataDir)
+	if err != nil {
+		tint.Err(err)
+		os.Exit(1)
+	}
+	cfg.DataDir = absDataDir
+
+	dataDirExists, err := utils.DoFolderExists(absDataDir)
+	if !dataDirExists {
+		slog.Error("You must set the --data-dir flag to an existing directory.")
+		os.Exit(2)
+	}
+	if err != nil {
+		tint.Err(err)
+		os.Exit(1)
+	}
+
+	if *from == "" || *to == "" {
+		slog.Error("--from and --to can't be empty.")
+		os.Exit(2)
+	}
+
+	return Opts{
+		dataDir:    absDataDir,
+		isTmpDir:   absDataDir == tmpDir,
+		from:       *from,
+		to:         *to,
+		reportPath: *reportPath,
+		config:     cfg,
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+	"path"
+
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/logger"
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/parser"
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/scraper"
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/utils"
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/writer"
+)
+
+func main() {
+	slog.SetDefault(logger.GetDefaultLogger())
+	opts := ParseOpts()
+	slog.SetDefault(logger.NewLogger(opts.config.Log))
+	utils.ConfigureHttp(opts.config.Http.Options(""))
+	slog.Info("argv validation", "data_dir", opts.dataDir, "from", opts.from, "to", opts.to)
+
+	from, err := parser.LoadManifestiSource(opts.from, opts.dataDir, opts.config.Urls.GithubRawData)
+	if err != nil {
+		slog.Error("could not load --from manifesti", "error", err)
+		os.Exit(1)
+	}
+
+	to, err := parser.LoadManifestiSource(opts.to, opts.dataDir, opts.config.Urls.GithubRawData)
+	if err != nil {
+		slog.Error("could not load --to manifesti", "error", err)
+		os.Exit(1)
+	}
+
+	diff := scraper.DiffManifesti(opts.from, from, opts.to, to)
+	diff.Log()
+
+	if opts.reportPath != "" {
+		w := writer.NewWriter[scraper.ManifestiDiff](path.Dir(opts.reportPath))
+		if err := w.JsonWrite(path.Base(opts.reportPath), diff, true); err != nil {
+			slog.Error("could not write manifesti diff report", "path", opts.reportPath, "error", err)
+			os.Exit(1)
+		}
+		slog.Info("manifesti diff report written", "path", opts.reportPath)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/config"
 	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/utils"
 	"github.com/lmittmann/tint"
 	"github.com/pborman/getopt/v2"
@@ -14,6 +15,7 @@ type Opts struct {
 	dataDir  string
 	htmlDir  string
 	isTmpDir bool
+	config   config.Config
 }
 
 func ParseOpts() Opts {
@@ -21,6 +23,7 @@ func ParseOpts() Opts {
 
 	// definition
 	help := getopt.BoolLong("help", 'h', "Shows the help menu")
+	configPath := getopt.StringLong("config", 'c', "", "Path of the config file (yaml or toml). Defaults to RANKINGS_CONFIG env, if set")
 	htmlDir := getopt.StringLong("html-dir", 'i', "", "Path of the folder containing the old html files.")
 	dataDir := getopt.StringLong("data-dir", 'o', tmpDir, "Path of the new data folder (containing html, json, ...). Defaults to tmp directory")
 
@@ -37,17 +40,29 @@ func ParseOpts() Opts {
 		os.Exit(2)
 	}
 
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		slog.Error("Could not load config.", "error", err)
+		os.Exit(2)
+	}
+
+	// flags override config file and env
+	if getopt.IsSet("data-dir") || cfg.DataDir == "" {
+		cfg.DataDir = *dataDir
+	}
+
 	absHtmlDir, err := filepath.Abs(*htmlDir)
 	if err != nil {
 		tint.Err(err)
 		os.Exit(1)
 	}
 
-	absDataDir, err := filepath.Abs(*dataDir)
+	absDataDir, err := filepath.Abs(cfg.DataDir)
 	if err != nil {
 		tint.Err(err)
 		os.Exit(1)
 	}
+	cfg.DataDir = absDataDir
 
 	dataDirExists, err := utils.DoFolderExists(absDataDir)
 	if !dataDirExists {
@@ -63,5 +78,6 @@ func ParseOpts() Opts {
 		dataDir:  absDataDir,
 		htmlDir:  absHtmlDir,
 		isTmpDir: absDataDir == tmpDir,
+		config:   cfg,
 	}
 }
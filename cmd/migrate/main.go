@@ -16,6 +16,7 @@ import (
 func main() {
 	slog.SetDefault(logger.GetDefaultLogger())
 	opts := ParseOpts()
+	slog.SetDefault(logger.NewLogger(opts.config.Log))
 	htmlOutDir := path.Join(opts.dataDir, constants.OutputHtmlFolder) // abs path
 
 	slog.Info("argv validation", "html_dir", opts.htmlDir, "data_dir", opts.dataDir, "html_out_dir", htmlOutDir)
@@ -89,10 +90,7 @@ func OrganizeHtml(inputPath string, outDir string) {
 	pathSplitted := strings.Split(inputPath, "/")
 	id := pathSplitted[len(pathSplitted)-1]
 	outRoot := path.Join(outDir, id)
-	w, err := writer.NewWriter[[]byte](outRoot)
-	if err != nil {
-		panic(err)
-	}
+	w := writer.NewWriter[[]byte](outRoot)
 
 	if err := w.Write(constants.OutputHtmlRanking_IndexFilename, index); err != nil {
 		slog.Error("Could not save ranking index html to filesystem", "ranking_url", inputPath)
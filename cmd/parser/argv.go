@@ -5,6 +5,8 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/config"
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/privacy"
 	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/utils"
 	"github.com/lmittmann/tint"
 	"github.com/pborman/getopt/v2"
@@ -13,6 +15,7 @@ import (
 type Opts struct {
 	dataDir  string
 	isTmpDir bool
+	config   config.Config
 }
 
 func ParseOpts() Opts {
@@ -20,7 +23,9 @@ func ParseOpts() Opts {
 
 	// definition
 	help := getopt.BoolLong("help", 'h', "Shows the help menu")
+	configPath := getopt.StringLong("config", 'c', "", "Path of the config file (yaml or toml). Defaults to RANKINGS_CONFIG env, if set")
 	dataDir := getopt.StringLong("data-dir", 'd', tmpDir, "Path of the data folder (containing html, json, ...). Defaults to tmp directory")
+	birthDatePolicy := getopt.StringLong("birth-date-policy", 0, "", "How students' birth date is published: keep, year-only, age-bucket, drop. Defaults to keep")
 
 	// parsing
 	getopt.Parse()
@@ -30,11 +35,32 @@ func ParseOpts() Opts {
 		os.Exit(0)
 	}
 
-	absDataDir, err := filepath.Abs(*dataDir)
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		slog.Error("Could not load config.", "error", err)
+		os.Exit(2)
+	}
+
+	// flags override config file and env
+	if getopt.IsSet("data-dir") || cfg.DataDir == "" {
+		cfg.DataDir = *dataDir
+	}
+
+	if getopt.IsSet("birth-date-policy") {
+		policy, err := privacy.ParseBirthDatePolicy(*birthDatePolicy)
+		if err != nil {
+			slog.Error("Invalid --birth-date-policy flag.", "error", err)
+			os.Exit(2)
+		}
+		cfg.Output.BirthDatePolicy = policy
+	}
+
+	absDataDir, err := filepath.Abs(cfg.DataDir)
 	if err != nil {
 		tint.Err(err)
 		os.Exit(1)
 	}
+	cfg.DataDir = absDataDir
 
 	dataDirExists, err := utils.DoFolderExists(absDataDir)
 	if !dataDirExists {
@@ -49,5 +75,6 @@ func ParseOpts() Opts {
 	return Opts{
 		dataDir:  absDataDir,
 		isTmpDir: absDataDir == tmpDir,
+		config:   cfg,
 	}
 }
@@ -15,11 +15,15 @@ import (
 func main() {
 	slog.SetDefault(logger.GetDefaultLogger())
 	opts := ParseOpts()
+	cfg := opts.config
+	slog.SetDefault(logger.NewLogger(cfg.Log))
 	manifestiOutDir := path.Join(opts.dataDir, constants.OutputBaseFolder, constants.OutputParsedManifestiFolder) // abs path
 	rankingsOutDir := path.Join(opts.dataDir, constants.OutputBaseFolder, constants.OutputParsedRankingsFolder)   // abs path
 	indexesOutDir := path.Join(opts.dataDir, constants.OutputBaseFolder, constants.OutputIndexesFolder)           // abs path
+	schemasOutDir := path.Join(opts.dataDir, constants.OutputBaseFolder, constants.OutputSchemasFolder)           // abs path
+	statsOutDir := path.Join(opts.dataDir, constants.OutputBaseFolder, constants.OutputRankingsStatsFolder)       // abs path
 
-	slog.Info("argv validation", "data_dir", opts.dataDir)
+	slog.Info("argv validation", "data_dir", opts.dataDir, "birth_date_policy", cfg.Output.BirthDatePolicy)
 
 	smWriter := writer.NewWriter[[]scraper.Manifesto](opts.dataDir)
 
@@ -33,7 +37,7 @@ func main() {
 
 	for _, m := range byDegTypeMans {
 		fn := utils.MakeFilename(m.DegreeType, ".json")
-		err := dtmWriter.JsonWrite(fn, m, false)
+		err := dtmWriter.JsonWrite(fn, m, cfg.Output.IndentManifesti)
 		if err != nil {
 			slog.Error("error while writing parsed manifesti byDegreeType (grouped)", "filename", fn)
 			panic(err)
@@ -46,7 +50,7 @@ func main() {
 	cmWriter := writer.NewWriter[parser.ManifestiByCourse](manifestiOutDir)
 
 	cmFn := constants.OutputParsedManifestiAllFilename
-	err = cmWriter.JsonWrite(cmFn, byCourseMans, false)
+	err = cmWriter.JsonWrite(cmFn, byCourseMans, cfg.Output.IndentManifesti)
 	if err != nil {
 		slog.Error("error while writing parsed manifesti byCourse (all)", "filename", cmFn)
 		panic(err)
@@ -54,6 +58,23 @@ func main() {
 
 	slog.Info("[manifesti] successful write", "filename", cmFn)
 
+	plansHtmlDir := path.Join(opts.dataDir, constants.OutputManifestiHtmlFolder)
+	plansOutDir := path.Join(manifestiOutDir, constants.OutputStudyPlansFolder)
+	if written, err := parser.WriteStudyPlans(plansHtmlDir, plansOutDir, inputMans, cfg.Output.IndentManifesti); err != nil {
+		slog.Error("could not write study plans", "error", err)
+	} else {
+		slog.Info("[manifesti] study plans successful write", "count", written)
+	}
+
+	snapshots, err := scraper.LoadManifestiSnapshots(path.Join(opts.dataDir, constants.OutputManifestiSnapshotsFolder))
+	if err != nil {
+		slog.Error("could not load manifesti snapshots", "error", err)
+	} else if err = parser.WriteManifestiAvailability(indexesOutDir, snapshots, cfg.Output.IndentIndexes); err != nil {
+		slog.Error("could not write manifesti availability index", "error", err)
+	} else {
+		slog.Info("[manifesti] successful write", "filename", constants.OutputIndexManifestiAvailabilityFilename, "snapshots", len(snapshots))
+	}
+
 	htmlFolderPath := path.Join(opts.dataDir, constants.OutputHtmlFolder)
 	htmlFolders, err := utils.GetEntriesInFolder(htmlFolderPath)
 	if err != nil {
@@ -63,10 +84,12 @@ func main() {
 
 	// note: this is hardcoded for testing
 	rankingWriter := writer.NewWriter[parser.Ranking](rankingsOutDir)
+	statsWriter := writer.NewWriter[*parser.RankingStats](statsOutDir)
 
-	indexGenerator := parser.NewIndexGenerator(indexesOutDir)
+	indexGenerator := parser.NewIndexGenerator(indexesOutDir, cfg.Output.IndentIndexes)
 
-	idHashIndexParser := parser.NewIdHashIndexParser(indexesOutDir)
+	idHashIndexParser := parser.NewIdHashIndexParser(indexesOutDir, cfg.Output.IndentIndexes)
+	parseReport := parser.NewParseReport()
 	for _, entry := range htmlFolders {
 		if !entry.IsDir() {
 			continue
@@ -81,21 +104,26 @@ func main() {
 		rp := parser.NewRankingParser(path.Join(opts.dataDir, constants.OutputHtmlFolder, id))
 
 		ranking := rp.Parse()
+		parseReport.Add(id, ranking)
 		if ranking == nil {
 			slog.Error("[rankings] could not parse. return nil", "id", id)
 			continue
 		}
 		indexGenerator.Add(ranking)
-
-		err = rankingWriter.JsonWrite(id+".json", *ranking, true)
 		idHashIndexParser.Add(ranking)
 
-		err = rankingWriter.JsonWrite(id+".json", *ranking, true)
+		ranking.ApplyBirthDatePolicy(cfg.Output.BirthDatePolicy)
+		err = rankingWriter.JsonWrite(id+".json", *ranking, cfg.Output.IndentRankings)
 		if err != nil {
 			slog.Error("[rankings] error while writing to fs (PANIC)", "id", id)
 			panic(err)
 		}
 
+		// compact, it is downloaded by the frontend
+		if err = statsWriter.JsonWrite(id+".json", parser.NewRankingStats(ranking), false); err != nil {
+			slog.Error("[rankings] error while writing stats", "id", id, "error", err)
+		}
+
 		slog.Info("[rankings] successful write", "id", id)
 	}
 
@@ -106,4 +134,12 @@ func main() {
 	if err = idHashIndexParser.Write(); err != nil {
 		slog.Error("could not write studentIdHashIndex.", "error", err)
 	}
+
+	if err = parseReport.Write(path.Join(opts.dataDir, constants.OutputBaseFolder), cfg.Output.IndentIndexes); err != nil {
+		slog.Error("could not write parse report.", "error", err)
+	}
+
+	if err = parser.WriteSchemas(schemasOutDir); err != nil {
+		slog.Error("could not write output schemas.", "error", err)
+	}
 }
@@ -70,7 +70,7 @@ func WriteReadParseCar() {
 		panic(err)
 	}
 
-	w, err := writer.NewWriter[Car](tmpFolder)
+	w := writer.NewWriter[Car](tmpFolder)
 	err = w.JsonWrite("car1.json", car1, false)
 	if err != nil {
 		panic(err)
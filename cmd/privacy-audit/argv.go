@@ -0,0 +1,92 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/config"
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/utils"
+	"github.com/lmittmann/tint"
+	"github.com/pborman/getopt/v2"
+)
+
+type Opts struct {
+	dataDir    string
+	isTmpDir   bool
+	k          int
+	reportPath string
+	redactDir  string // abs path, empty if not requested
+	config     config.Config
+}
+
+func ParseOpts() Opts {
+	tmpDir, _ := utils.TmpDirectory() // we don't care if err
+
+	// definition
+	help := getopt.BoolLong("help", 'h', "Shows the help menu")
+	configPath := getopt.StringLong("config", 'c', "", "Path of the config file (yaml or toml). Defaults to RANKINGS_CONFIG env, if set")
+	dataDir := getopt.StringLong("data-dir", 'd', tmpDir, "Path of the data folder (containing html, json, ...). Defaults to tmp directory")
+	k := getopt.IntLong("k", 'k', 2, "Minimum number of rows that must share the same quasi-identifiers. Defaults to 2 (only unique rows are flagged)")
+	reportPath := getopt.StringLong("report", 'r', "", "Path of the JSON file where the full report is written. If not set, only a summary is logged")
+	redactDir := getopt.StringLong("redact", 0, "", "Path of the folder where a redacted copy of the output tree is written. If not set, nothing is written")
+
+	// parsing
+	getopt.Parse()
+
+	if *help {
+		getopt.Usage()
+		os.Exit(0)
+	}
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		slog.Error("Could not load config.", "error", err)
+		os.Exit(2)
+	}
+
+	// flags override config file and env
+	if getopt.IsSet("data-dir") || cfg.DataDir == "" {
+		cfg.DataDir = *dataDir
+	}
+
+	absDataDir, err := filepath.Abs(cfg.DataDir)
+	if err != nil {
+		tint.Err(err)
+		os.Exit(1)
+	}
+	cfg.DataDir = absDataDir
+
+	dataDirExists, err := utils.DoFolderExists(absDataDir)
+	if !dataDirExists {
+		slog.Error("You must set the --data-dir flag to an existing directory.")
+		os.Exit(2)
+	}
+	if err != nil {
+		tint.Err(err)
+		os.Exit(1)
+	}
+
+	if *k < 2 {
+		slog.Error("The -k flag must be at least 2.", "k", *k)
+		os.Exit(2)
+	}
+
+	absRedactDir := ""
+	if *redactDir != "" {
+		absRedactDir, err = filepath.Abs(*redactDir)
+		if err != nil {
+			tint.Err(err)
+			os.Exit(1)
+		}
+	}
+
+	return Opts{
+		dataDir:    absDataDir,
+		isTmpDir:   absDataDir == tmpDir,
+		k:          *k,
+		reportPath: *reportPath,
+		redactDir:  absRedactDir,
+		config:     cfg,
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+	"path"
+
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/audit"
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/constants"
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/logger"
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/writer"
+)
+
+func main() {
+	slog.SetDefault(logger.GetDefaultLogger())
+	opts := ParseOpts()
+	slog.SetDefault(logger.NewLogger(opts.config.Log))
+
+	outputDir := path.Join(opts.dataDir, constants.OutputBaseFolder) // abs path
+	slog.Info("argv validation", "data_dir", opts.dataDir, "output_dir", outputDir, "k", opts.k)
+
+	auditor := audit.NewAuditor(outputDir, opts.k)
+	report, err := auditor.Run()
+	if err != nil {
+		slog.Error("could not audit output rankings", "error", err)
+		os.Exit(1)
+	}
+
+	for _, r := range report.Rankings {
+		if r.BelowK == 0 {
+			continue
+		}
+		slog.Warn("[privacy-audit] ranking at risk", "id", r.Id, "rows", r.Rows, "unique", r.Unique, "below_k", r.BelowK,
+			"avg_risk", r.AvgRisk, "max_risk", r.MaxRisk, "redaction_level", r.RedactionLevel, "residual", r.Residual)
+	}
+	slog.Info("[privacy-audit] audit finished", "rankings", len(report.Rankings), "rows", report.Rows, "unique", report.Unique, "below_k", report.BelowK)
+
+	if opts.reportPath != "" {
+		w := writer.NewWriter[audit.Report](path.Dir(opts.reportPath))
+		if err := w.JsonWrite(path.Base(opts.reportPath), report, true); err != nil {
+			slog.Error("could not write privacy audit report", "path", opts.reportPath, "error", err)
+		} else {
+			slog.Info("privacy audit report written", "path", opts.reportPath)
+		}
+	}
+
+	if opts.redactDir != "" {
+		if err := auditor.Redact(opts.redactDir, opts.config.Output.IndentRankings); err != nil {
+			slog.Error("could not write redacted output tree", "path", opts.redactDir, "error", err)
+			os.Exit(1)
+		}
+		slog.Info("[privacy-audit] redacted output tree written", "path", opts.redactDir)
+	}
+
+	if !report.Ok() {
+		os.Exit(1)
+	}
+}
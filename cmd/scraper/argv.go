@@ -4,7 +4,12 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/config"
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/parser"
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/scraper"
 	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/utils"
 	"github.com/lmittmann/tint"
 	"github.com/pborman/getopt/v2"
@@ -13,6 +18,7 @@ import (
 type BruteforceOpt struct {
 	enabled bool
 	year    uint
+	analyze bool
 }
 
 type Opts struct {
@@ -20,7 +26,14 @@ type Opts struct {
 	isTmpDir bool
 	force    bool
 
+	linkSources []string
+
+	checkManifesti   bool
+	compareManifesti string // source, see parser.LoadManifestiSource
+	manifestiReport  string
+
 	bruteforce BruteforceOpt
+	config     config.Config
 }
 
 func ParseOpts() Opts {
@@ -28,9 +41,36 @@ func ParseOpts() Opts {
 
 	// definition
 	help := getopt.BoolLong("help", 'h', "Shows the help menu")
+	configPath := getopt.StringLong("config", 'c', "", "Path of the config file (yaml or toml). Defaults to RANKINGS_CONFIG env, if set")
 	dataDir := getopt.StringLong("data-dir", 'd', tmpDir, "Path of the data folder (containing html, json, ...). Defaults to tmp directory")
 	force := getopt.BoolLong("force", 'f', "Force the scraper to run and overwrite files")
 	bruteforce := getopt.UintLong("bruteforce", 'b', 0, "If you need to run bruteforce link scraper, use this option and specify the year to bruteforce as value")
+	bfWorkers := getopt.IntLong("bruteforce-workers", 0, 0, "Number of concurrent HTTP requests of the bruteforce (config: bruteforce.workers)")
+	bfRps := getopt.IntLong("bruteforce-rps", 0, 0, "Max requests per second of the bruteforce, 0 = unlimited (config: bruteforce.rps)")
+	bfAnalyze := getopt.BoolLong("bruteforce-analyze", 0, "Only analyze the saved HTML folders: log and write the learned phase IDs and hex ranges to links/bruteforce/model.json")
+	bfExhaustive := getopt.BoolLong("bruteforce-exhaustive", 0, "Test every hex suffix of every phase ID, without early stop (config: bruteforce.earlyStop)")
+	bfTimeout := getopt.DurationLong("bruteforce-timeout", 0, 0, "Per-request timeout of the bruteforce, e.g. 10s (config: bruteforce.timeout)")
+	bfBudget := getopt.IntLong("bruteforce-budget", 0, 0, "Max HTTP requests of the bruteforce in this run, 0 = unlimited, the next run resumes it (config: bruteforce.budget, default 20000)")
+
+	linkSources := getopt.StringLong("link-sources", 0, strings.Join(scraper.LinkSources, ","), "Comma separated sources of rankings links: "+strings.Join(scraper.LinkSources, ", "))
+
+	httpBudget := getopt.IntLong("http-host-budget", 0, 0, "Max HTTP requests per host in this run, not counting the bruteforce ones, 0 = unlimited (config: http.hostBudget, default 5000)")
+	warc := getopt.BoolLong("warc", 0, "Archive every fetched page in WARC files under <data-dir>/warc (config: archive.warc)")
+	noCache := getopt.BoolLong("no-cache", 0, "Download every page again, without using nor updating the HTTP cache (config: http.cache.enabled)")
+	noRobots := getopt.BoolLong("no-robots", 0, "Do not honour robots.txt (config: http.robots)")
+
+	checkManifesti := getopt.BoolLong("check-manifesti", 0, "Only check which manifesti discovery strategies work, exit 1 if the Polimi layout changed")
+	compareManifesti := getopt.StringLong("compare-manifesti", 0, parser.ManifestiSourceRemote, "Source to compare the scraped manifesti with: remote, git:<revision> or the path of a JSON file. Empty to skip")
+	manifestiReport := getopt.StringLong("manifesti-report", 0, "", "Path of the JSON file where the manifesti diff is written. If not set, it is only logged")
+
+	notifyWebhook := getopt.StringLong("notify-webhook", 0, "", "URL to POST a JSON payload to when new rankings are found")
+	notifyTelegram := getopt.StringLong("notify-telegram-chat", 0, "", "Telegram chat id to notify when new rankings are found (requires TELEGRAM_BOT_TOKEN env)")
+	notifySmtp := getopt.StringLong("notify-smtp-host", 0, "", "SMTP server (host:port) used to send email notifications (SMTP_USERNAME and SMTP_PASSWORD env)")
+	notifyFrom := getopt.StringLong("notify-email-from", 0, "", "Sender address of email notifications")
+	notifyTo := getopt.StringLong("notify-email-to", 0, "", "Comma separated recipients of email notifications")
+	notifyCmd := getopt.StringLong("notify-cmd", 0, "", "Shell command to run when new rankings are found (payload JSON on stdin)")
+	notifyRetries := getopt.IntLong("notify-retries", 0, 3, "Number of retries for each failed notification")
+	notifyDryRun := getopt.BoolLong("notify-dry-run", 0, "Log notifications instead of sending them")
 
 	// parsing
 	getopt.Parse()
@@ -40,11 +80,84 @@ func ParseOpts() Opts {
 		os.Exit(0)
 	}
 
-	absDataDir, err := filepath.Abs(*dataDir)
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		slog.Error("Could not load config.", "error", err)
+		os.Exit(2)
+	}
+
+	// flags override config file and env
+	if getopt.IsSet("data-dir") || cfg.DataDir == "" {
+		cfg.DataDir = *dataDir
+	}
+	if getopt.IsSet("bruteforce-workers") {
+		cfg.Bruteforce.Workers = *bfWorkers
+	}
+	if getopt.IsSet("bruteforce-rps") {
+		cfg.Bruteforce.Rps = *bfRps
+	}
+	if getopt.IsSet("bruteforce-timeout") {
+		cfg.Bruteforce.Timeout = *bfTimeout
+	}
+	if getopt.IsSet("bruteforce-budget") {
+		cfg.Bruteforce.Budget = *bfBudget
+	}
+	if getopt.IsSet("http-host-budget") {
+		cfg.Http.HostBudget = *httpBudget
+	}
+	if getopt.IsSet("warc") {
+		cfg.Archive.Warc = *warc
+	}
+	if getopt.IsSet("no-cache") {
+		cfg.Http.Cache.Enabled = !*noCache
+	}
+	if getopt.IsSet("no-robots") {
+		cfg.Http.Robots = !*noRobots
+	}
+	if getopt.IsSet("bruteforce-exhaustive") {
+		cfg.Bruteforce.EarlyStop = !*bfExhaustive
+	}
+	if getopt.IsSet("notify-webhook") {
+		cfg.Notify.WebhookUrl = *notifyWebhook
+	}
+	if getopt.IsSet("notify-telegram-chat") {
+		cfg.Notify.TelegramChat = *notifyTelegram
+	}
+	if getopt.IsSet("notify-smtp-host") {
+		cfg.Notify.SmtpHost = *notifySmtp
+	}
+	if getopt.IsSet("notify-email-from") {
+		cfg.Notify.EmailFrom = *notifyFrom
+	}
+	if getopt.IsSet("notify-email-to") {
+		cfg.Notify.EmailTo = []string{}
+		for _, to := range strings.Split(*notifyTo, ",") {
+			if to = strings.TrimSpace(to); to != "" {
+				cfg.Notify.EmailTo = append(cfg.Notify.EmailTo, to)
+			}
+		}
+	}
+	if getopt.IsSet("notify-cmd") {
+		cfg.Notify.Command = *notifyCmd
+	}
+	if getopt.IsSet("notify-retries") {
+		cfg.Notify.Retries = *notifyRetries
+	}
+	if getopt.IsSet("notify-dry-run") {
+		cfg.Notify.DryRun = *notifyDryRun
+	}
+
+	if err := cfg.Validate(); err != nil {
+		slog.Error("Invalid config.", "error", err)
+		os.Exit(2)
+	}
+
+	absDataDir, err := filepath.Abs(cfg.DataDir)
 	if err != nil {
 		tint.Err(err)
 		os.Exit(1)
 	}
+	cfg.DataDir = absDataDir
 
 	dataDirExists, err := utils.DoFolderExists(absDataDir)
 	if !dataDirExists {
@@ -62,14 +175,44 @@ func ParseOpts() Opts {
 		os.Exit(2)
 	}
 
+	if cfg.Notify.TelegramChat != "" && cfg.Notify.TelegramTok == "" {
+		slog.Error("You must set the TELEGRAM_BOT_TOKEN env variable (or notify.telegramToken) to use --notify-telegram-chat.")
+		os.Exit(2)
+	}
+
+	if cfg.Notify.SmtpHost != "" && (len(cfg.Notify.EmailTo) == 0 || cfg.Notify.EmailFrom == "") {
+		slog.Error("You must set --notify-email-from and --notify-email-to to use --notify-smtp-host.")
+		os.Exit(2)
+	}
+
+	sources := []string{}
+	for _, s := range strings.Split(*linkSources, ",") {
+		if s = strings.TrimSpace(s); s == "" {
+			continue
+		}
+		if !slices.Contains(scraper.LinkSources, s) {
+			slog.Error("Unknown link source.", "source", s, "available", scraper.LinkSources)
+			os.Exit(2)
+		}
+		sources = append(sources, s)
+	}
+
 	return Opts{
 		dataDir:  absDataDir,
 		isTmpDir: absDataDir == tmpDir,
 		force:    *force,
 
+		linkSources: sources,
+
+		checkManifesti:   *checkManifesti,
+		compareManifesti: *compareManifesti,
+		manifestiReport:  *manifestiReport,
+
 		bruteforce: BruteforceOpt{
 			enabled: bfYear != 0,
 			year:    bfYear,
+			analyze: *bfAnalyze,
 		},
+		config: cfg,
 	}
 }
@@ -4,16 +4,12 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
 	"log/slog"
-	"net/http"
-	"net/url"
 	"os"
 	"path"
-	"reflect"
-	"slices"
-	"strings"
+	"time"
 
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/config"
 	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/constants"
 	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/logger"
 	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/parser"
@@ -26,48 +22,78 @@ func main() {
 	slog.SetDefault(logger.GetDefaultLogger())
 
 	opts := ParseOpts()
+	cfg := opts.config
+	slog.SetDefault(logger.NewLogger(cfg.Log))
+	utils.ConfigureHttp(cfg.Http.Options(path.Join(opts.dataDir, constants.OutputHttpCacheFolder)))
 
 	manifestiOutDir := opts.dataDir
+	studyPlansDir := path.Join(opts.dataDir, constants.OutputManifestiHtmlFolder)
 	linksOutDir := path.Join(opts.dataDir, constants.OutputLinksFolder)
 	bfLinksOutDir := path.Join(opts.dataDir, constants.OutputLinksFolder, constants.OutputBruteForceFolder)
 	savedHtmlsFolder := path.Join(opts.dataDir, constants.OutputHtmlFolder)
 
+	if cfg.Archive.Warc {
+		warcWriter, err := utils.NewWarcWriter(path.Join(opts.dataDir, constants.OutputWarcFolder), "rankings")
+		if err != nil {
+			slog.Error("could not create WARC archive", "error", err)
+			os.Exit(1)
+		}
+		utils.ConfigureWarc(warcWriter)
+		defer warcWriter.Close()
+	}
+
 	if opts.isTmpDir {
 		slog.Warn("ATTENION! using tmp directory instead of data directory. Check --help for more information on data dir.", "dataDir", opts.dataDir)
 	} else {
 		slog.Info("Argv validation", "data_dir", opts.dataDir)
 	}
 
-	mansWriter := writer.NewWriter[[]scraper.Manifesto](manifestiOutDir)
-	mans := scrapeManifestiWithLocal(&mansWriter, opts.force)
-
-	slog.Info("finished scraping manifesti, writing to file...", "found", len(mans))
-
-	err := mansWriter.JsonWrite(constants.OutputManifestiListFilename, mans, false)
-	if err != nil {
-		panic(err)
+	if opts.bruteforce.analyze {
+		model := scraper.AnalyzeSavedHtmls(savedHtmlsFolder)
+		model.Log()
+		w := writer.NewWriter[*scraper.BruteforceModel](bfLinksOutDir)
+		if err := w.JsonWrite(constants.OutputBruteForceModelFilename, model, true); err != nil {
+			slog.Error("could not write bruteforce model", "error", err)
+			os.Exit(1)
+		}
+		return
 	}
 
-	slog.Info("successfully written manifesti to file!")
-
-	manEquals, err := doLocalEqualsRemoteManifesti(&mansWriter)
-	if err != nil {
-		slog.Error("cannot perform comparison between local and remote versions", "err", err)
+	if opts.checkManifesti {
+		report, err := scraper.CheckManifestiDiscovery(cfg.Urls.SchoolCoursePages, cfg.Urls.CourseList)
+		report.Log()
+		if err != nil {
+			slog.Error("manifesti discovery check failed", "error", err)
+			os.Exit(1)
+		}
+		slog.Info("manifesti discovery check passed")
 		return
 	}
 
-	slog.Info("Scrape manifesti, equals to remote version??", "equals", manEquals)
+	mansWriter := writer.NewWriter[[]scraper.Manifesto](manifestiOutDir)
+	mans, listedMans, err := scrapeManifestiWithLocal(&mansWriter, opts.force, cfg.Urls, studyPlansDir)
+	if err != nil {
+		// rankings don't depend on the manifesti, so we go on
+		slog.Error("could not scrape manifesti, the local ones are kept. Run with --check-manifesti for details", "error", err)
+	} else {
+		saveManifesti(&mansWriter, mans, listedMans, opts)
+	}
 
 	slog.Info("------------------------------------------")
 	slog.Info("START scraping new rankings links")
 
 	linksManager := scraper.NewLinksManager(linksOutDir)
 	linksManager.PrintState("init")
-	scrapedNewLinks := linksManager.FilterNewLinks(scraper.ScrapeRankingsLinks())
+	discoveredLinks, sourceResults := scraper.DiscoverRankingsLinks(newLinkSources(opts.linkSources, cfg.Urls, linksOutDir))
+	sourcesWriter := writer.NewWriter[[]scraper.SourceResult](linksOutDir)
+	if err := sourcesWriter.JsonWrite(constants.OutputLinkSourcesFilename, sourceResults, true); err != nil {
+		slog.Error("could not write link sources results", "error", err)
+	}
+	scrapedNewLinks := linksManager.FilterNewLinks(discoveredLinks)
 
 	bruteforceNewLinks := []string{}
 	if opts.bruteforce.enabled {
-		bruteforcer := scraper.NewBruteforcer(bfLinksOutDir, savedHtmlsFolder, opts.bruteforce.year)
+		bruteforcer := scraper.NewBruteforcer(bfLinksOutDir, savedHtmlsFolder, opts.bruteforce.year, cfg.Urls.RisultatiAmmissioneDomain, cfg.Bruteforce)
 		bruteforceNewLinks = linksManager.FilterNewLinks(bruteforcer.Start())
 	}
 	linksManager.PrintState("after bruteforce")
@@ -76,13 +102,44 @@ func main() {
 	linksManager.SetNewLinks(scrapedLinks, brokenLinks)
 	linksManager.PrintState("after download HTMLs")
 
+	notifyNewRankings(newNotifier(cfg.Notify), linksManager.NewScrapedLinks(), savedHtmlsFolder)
+
 	linksManager.Write(opts.force)
 
 	slog.Info("END scraping new rankings links", "scrapedCount", len(scrapedLinks), "brokenCount", len(brokenLinks))
+	utils.LogHttpMetrics()
 
 	slog.Info("------------------------------------------")
 }
 
+// newLinkSources returns the enabled link sources, skipping (with a warning) the ones without a configured url
+func newLinkSources(names []string, urls config.UrlsConfig, linksDir string) []scraper.LinkSource {
+	sources := []scraper.LinkSource{}
+	for _, name := range names {
+		switch name {
+		case scraper.LinkSourceAvvisi:
+			sources = append(sources, scraper.NewNewsSource(name, urls.AvvisiFuturiStudenti, urls.RisultatiAmmissioneDomain))
+		case scraper.LinkSourceNewsEn:
+			if urls.NewsEn == "" {
+				slog.Warn("[link-discovery] urls.newsEn not set, skipping source. Set it in the config or leave the source out of --link-sources", "source", name)
+				continue
+			}
+			sources = append(sources, scraper.NewNewsSource(name, urls.NewsEn, urls.RisultatiAmmissioneDomain))
+		case scraper.LinkSourceSchoolPages:
+			if len(urls.SchoolAdmissionPages) == 0 {
+				slog.Warn("[link-discovery] urls.schoolAdmissionPages not set, skipping source. Set it in the config or leave the source out of --link-sources", "source", name)
+				continue
+			}
+			sources = append(sources, scraper.NewPagesSource(name, urls.SchoolAdmissionPages, urls.RisultatiAmmissioneDomain))
+		case scraper.LinkSourceDirectory:
+			sources = append(sources, scraper.NewDirectorySource(urls.RisultatiAmmissioneDomain))
+		case scraper.LinkSourceManual:
+			sources = append(sources, scraper.NewManualSource(linksDir))
+		}
+	}
+	return sources
+}
+
 func downloadHTMLs(newLinks []string, outDir string) ([]string, []string) {
 	scrapedLinks := []string{}
 	brokenLinks := []string{}
@@ -105,41 +162,11 @@ func downloadHTMLs(newLinks []string, outDir string) ([]string, []string) {
 			continue
 		}
 
-		root := path.Join(outDir, r.Id) // path of this ranking's html root folder
-		w := writer.NewWriter[[]byte](root)
-
-		if err := w.Write(constants.OutputHtmlRanking_IndexFilename, r.Index.Content); err != nil {
-			slog.Error("Could not save ranking index html to filesystem", "ranking_url", r.Url.String())
+		if err := r.Save(outDir); err != nil {
+			slog.Error("Could not save ranking html to filesystem", "ranking_url", r.Url.String())
 			panic(err)
 		}
 
-		// update writer outDir path to byMerit folder
-		w.ChangeDirPath(path.Join(root, constants.OutputHtmlRanking_ByMeritFolder))
-		for _, page := range r.ByMerit {
-			if err := w.Write(page.Id, page.Content); err != nil {
-				slog.Error("Could not save ranking byMerit table html to filesystem", "ranking_url", r.Url.String(), "page_id", page.Id)
-				panic(err)
-			}
-		}
-
-		// update writer outDir path to byId folder
-		w.ChangeDirPath(path.Join(root, constants.OutputHtmlRanking_ByIdFolder))
-		for _, page := range r.ById {
-			if err := w.Write(page.Id, page.Content); err != nil {
-				slog.Error("Could not save ranking byId table html to filesystem", "ranking_url", r.Url.String(), "page_id", page.Id)
-				panic(err)
-			}
-		}
-
-		// update writer outDir path to byCourse folder
-		w.ChangeDirPath(path.Join(root, constants.OutputHtmlRanking_ByCourseFolder))
-		for _, page := range r.ByCourse {
-			if err := w.Write(page.Id, page.Content); err != nil {
-				slog.Error("Could not save ranking byCourse table html to filesystem", "ranking_url", r.Url.String(), "page_id", page.Id)
-				panic(err)
-			}
-		}
-
 		scrapedLinks = append(scrapedLinks, r.Url.String())
 		downloadedCount += r.PageCount
 	}
@@ -148,14 +175,14 @@ func downloadHTMLs(newLinks []string, outDir string) ([]string, []string) {
 	return scrapedLinks, brokenLinks
 }
 
-func scrapeManifestiWithLocal(w *writer.Writer[[]scraper.Manifesto], force bool) []scraper.Manifesto {
+func scrapeManifestiWithLocal(w *writer.Writer[[]scraper.Manifesto], force bool, urls config.UrlsConfig, studyPlansDir string) ([]scraper.Manifesto, []scraper.Manifesto, error) {
 	fn := constants.OutputManifestiListFilename
 	fp := w.GetFilePath(fn)
 	slog := slog.With("filepath", fp)
 
 	if force {
 		slog.Info("Scraping manifesti because of -f flag")
-		return scraper.ScrapeManifesti(nil)
+		return scraper.ScrapeManifesti(nil, urls.SchoolCoursePages, urls.CourseList, studyPlansDir)
 	}
 
 	local, err := w.JsonRead(fn)
@@ -170,95 +197,66 @@ func scrapeManifestiWithLocal(w *writer.Writer[[]scraper.Manifesto], force bool)
 		default:
 			slog.Error("Failed to read from manifesti json file, running scraper...", "error", err)
 		}
-		return scraper.ScrapeManifesti(nil)
+		return scraper.ScrapeManifesti(nil, urls.SchoolCoursePages, urls.CourseList, studyPlansDir)
 	}
 
 	if len(local) == 0 {
 		slog.Info(fmt.Sprintf("%s file is empty, running scraper...", fn))
-		return scraper.ScrapeManifesti(nil)
+		return scraper.ScrapeManifesti(nil, urls.SchoolCoursePages, urls.CourseList, studyPlansDir)
 	}
 
 	slog.Info(fmt.Sprintf("loaded %d manifesti from %s json file, running scraper to check if there are new ones. If you would like to regenerate the whole thing, use the -f flag.", len(local), fn))
-	return scraper.ScrapeManifesti(local)
+	return scraper.ScrapeManifesti(local, urls.SchoolCoursePages, urls.CourseList, studyPlansDir)
 }
 
-func GetRemoteManifesti() ([]byte, []scraper.Manifesto, error) {
-	remotePath, err := url.JoinPath(constants.WebGithubMainRawDataUrl, constants.OutputBaseFolder, "manifesti.json") // this is still the old filename
-	slog.Info("remote manifesti file", "url", remotePath)
-	if err != nil {
-		return nil, nil, err
+// compareManifesti logs (and writes to reportPath, if set) the diff between the source and the scraped manifesti
+func compareManifesti(mans []scraper.Manifesto, source, reportPath, dataDir, rawDataUrl string) {
+	if source == "" {
+		return
 	}
 
-	res, err := http.Get(remotePath)
+	old, err := parser.LoadManifestiSource(source, dataDir, rawDataUrl)
 	if err != nil {
-		return nil, nil, err
-	}
-	defer res.Body.Close()
-	bytes, err := io.ReadAll(res.Body)
-	if err != nil {
-		return nil, nil, err
+		slog.Error("cannot compare scraped manifesti", "source", source, "error", err)
+		return
 	}
 
-	out := parser.RemoteManifesti{}
-	err = json.Unmarshal(bytes, &out.Data)
-	if err != nil {
-		return bytes, nil, err
-	}
+	diff := scraper.DiffManifesti(source, old, "scraped", mans)
+	diff.Log()
 
-	return bytes, out.ToList(), err
+	if reportPath != "" {
+		w := writer.NewWriter[scraper.ManifestiDiff](path.Dir(reportPath))
+		if err := w.JsonWrite(path.Base(reportPath), diff, true); err != nil {
+			slog.Error("could not write manifesti diff report", "path", reportPath, "error", err)
+		}
+	}
 }
 
-func doLocalEqualsRemoteManifesti(w *writer.Writer[[]scraper.Manifesto]) (bool, error) {
-	localSlice, err := w.JsonRead(constants.OutputManifestiListFilename)
-	if err != nil {
-		return false, err
-	}
+// saveManifesti writes the scraped manifesti, the snapshots of the ones listed in this run and
+// the study plans, and compares them with the configured source
+func saveManifesti(w *writer.Writer[[]scraper.Manifesto], mans, listed []scraper.Manifesto, opts Opts) {
+	slog.Info("finished scraping manifesti, writing to file...", "found", len(mans))
 
-	_, remoteSlice, err := GetRemoteManifesti()
+	err := w.JsonWrite(constants.OutputManifestiListFilename, mans, false)
 	if err != nil {
-		return false, err
+		panic(err)
 	}
 
-	slices.SortStableFunc(localSlice, func(a, b scraper.Manifesto) int {
-		name := strings.Compare(a.Name, b.Name)
-		if name != 0 {
-			return name
-		}
-
-		return strings.Compare(a.Location, b.Location)
-	})
-
-	slices.SortStableFunc(remoteSlice, func(a, b scraper.Manifesto) int {
-		name := strings.Compare(a.Name, b.Name)
-		if name != 0 {
-			return name
-		}
-
-		return strings.Compare(a.Location, b.Location)
-	})
+	slog.Info("successfully written manifesti to file!")
 
-	if len(localSlice) != len(remoteSlice) {
-		return false, nil
+	snapshotsDir := path.Join(opts.dataDir, constants.OutputManifestiSnapshotsFolder)
+	if written, err := scraper.WriteManifestiSnapshots(snapshotsDir, listed, time.Now()); err != nil {
+		slog.Error("could not write manifesti snapshots", "error", err)
+	} else {
+		slog.Info("manifesti snapshots", "written", written)
 	}
 
-	// Politecnico loves changing domains and web servers
-	// this is to not false our check
-	for i := range len(localSlice) {
-		rUrl, err := url.Parse(remoteSlice[i].Url)
-		if err != nil {
-			return false, err
-		}
-		lUrl, err := url.Parse(localSlice[i].Url)
-		if err != nil {
-			return false, err
-		}
-
-		lUrl.Host = "DOMAIN.polimi.it"
-		rUrl.Host = "DOMAIN.polimi.it"
-
-		localSlice[i].Url = lUrl.String()
-		remoteSlice[i].Url = rUrl.String()
+	studyPlansDir := path.Join(opts.dataDir, constants.OutputManifestiHtmlFolder)
+	if downloaded, err := scraper.DownloadStudyPlans(mans, studyPlansDir); err != nil {
+		slog.Error("could not download study plans", "error", err)
+	} else {
+		slog.Info("study plans downloaded", "count", downloaded)
 	}
 
-	return reflect.DeepEqual(localSlice, remoteSlice), nil
+	compareManifesti(mans, opts.compareManifesti, opts.manifestiReport, opts.dataDir, opts.config.Urls.GithubRawData)
 }
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"log/slog"
+	"path"
+	"time"
+
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/config"
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/notifier"
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/parser"
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/scraper"
+)
+
+func newNotifier(cfg config.NotifyConfig) *notifier.Notifier {
+	n := notifier.NewNotifier(cfg.Retries, 5*time.Second, cfg.DryRun)
+
+	if cfg.WebhookUrl != "" {
+		n.AddSink(notifier.NewWebhookSink(cfg.WebhookUrl))
+	}
+
+	if cfg.TelegramChat != "" {
+		n.AddSink(notifier.NewTelegramSink(cfg.TelegramTok, cfg.TelegramChat))
+	}
+
+	if cfg.SmtpHost != "" {
+		n.AddSink(notifier.NewEmailSink(cfg.SmtpHost, cfg.SmtpUser, cfg.SmtpPassword, cfg.EmailFrom, cfg.EmailTo))
+	}
+
+	if cfg.Command != "" {
+		n.AddSink(notifier.NewCommandSink(cfg.Command))
+	}
+
+	return n
+}
+
+// notifyNewRankings parses the just downloaded rankings to get school, year and phase
+// and sends them to every configured sink
+func notifyNewRankings(n *notifier.Notifier, newLinks []string, savedHtmlsFolder string) {
+	if !n.HasSinks() {
+		slog.Debug("[notifier] no sink configured, skipping notifications")
+		return
+	}
+
+	rankings := make([]notifier.NewRanking, 0, len(newLinks))
+	for _, link := range newLinks {
+		id, err := scraper.RankingIdFromLink(link)
+		if err != nil {
+			slog.Error("[notifier] could not parse new ranking link", "link", link, "error", err)
+			continue
+		}
+
+		nr := notifier.NewRanking{Id: id, Link: link}
+
+		ranking := parser.NewRankingParser(path.Join(savedHtmlsFolder, id)).Parse()
+		if ranking != nil {
+			nr.School = ranking.School
+			nr.Year = ranking.Year
+			nr.Phase = ranking.Phase.Stripped
+		} else {
+			slog.Warn("[notifier] could not parse new ranking, notifying with link only", "id", id)
+		}
+
+		rankings = append(rankings, nr)
+	}
+
+	if err := n.Notify(rankings); err != nil {
+		slog.Error("[notifier] some notifications were not sent", "error", err)
+	}
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+	"path"
+
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/constants"
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/logger"
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/parser"
+)
+
+func main() {
+	slog.SetDefault(logger.GetDefaultLogger())
+	opts := ParseOpts()
+	slog.SetDefault(logger.NewLogger(opts.config.Log))
+
+	outputDir := path.Join(opts.dataDir, constants.OutputBaseFolder) // abs path
+	seatFlowOutDir := path.Join(outputDir, constants.OutputSeatFlowFolder)
+	slog.Info("argv validation", "data_dir", opts.dataDir)
+
+	rankings, err := parser.LoadRankings(outputDir)
+	if err != nil {
+		slog.Error("could not load parsed rankings", "error", err)
+		os.Exit(1)
+	}
+
+	flows := parser.SeatFlows(rankings)
+	for _, f := range flows {
+		for _, s := range f.Streams {
+			slog.Info("[seat-flow] stream analysed", "school", f.School, "year", f.Year, "language", s.Language, "extra_eu", s.IsExtraEu,
+				"rankings", len(s.Rankings), "courses", len(s.Courses), "tracked", s.Tracked)
+		}
+	}
+
+	if err := parser.WriteSeatFlows(seatFlowOutDir, flows, opts.config.Output.IndentIndexes); err != nil {
+		slog.Error("could not write seat flows", "error", err)
+		os.Exit(1)
+	}
+
+	slog.Info("[seat-flow] successful write", "files", len(flows))
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/config"
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/consistency"
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/utils"
+	"github.com/lmittmann/tint"
+	"github.com/pborman/getopt/v2"
+)
+
+type Opts struct {
+	dataDir    string
+	isTmpDir   bool
+	reportPath string
+	repair     []consistency.RepairAction
+	config     config.Config
+}
+
+func ParseOpts() Opts {
+	tmpDir, _ := utils.TmpDirectory() // we don't care if err
+
+	// definition
+	help := getopt.BoolLong("help", 'h', "Shows the help menu")
+	configPath := getopt.StringLong("config", 'c', "", "Path of the config file (yaml or toml). Defaults to RANKINGS_CONFIG env, if set")
+	dataDir := getopt.StringLong("data-dir", 'd', tmpDir, "Path of the data folder (containing html, json, ...). Defaults to tmp directory")
+	reportPath := getopt.StringLong("report", 'r', "", "Path of the JSON file where the full report is written. If not set, only a summary is logged")
+	repair := getopt.ListLong("repair", 0, "Comma separated repair actions for consistency issues: delete (orphan outputs, empty html folders), download (missing/incomplete html), parse (html without output)")
+
+	// parsing
+	getopt.Parse()
+
+	if *help {
+		getopt.Usage()
+		os.Exit(0)
+	}
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		slog.Error("Could not load config.", "error", err)
+		os.Exit(2)
+	}
+
+	// flags override config file and env
+	if getopt.IsSet("data-dir") || cfg.DataDir == "" {
+		cfg.DataDir = *dataDir
+	}
+
+	absDataDir, err := filepath.Abs(cfg.DataDir)
+	if err != nil {
+		tint.Err(err)
+		os.Exit(1)
+	}
+	cfg.DataDir = absDataDir
+
+	dataDirExists, err := utils.DoFolderExists(absDataDir)
+	if !dataDirExists {
+		slog.Error("You must set the --data-dir flag to an existing directory.")
+		os.Exit(2)
+	}
+	if err != nil {
+		tint.Err(err)
+		os.Exit(1)
+	}
+
+	repairActions := []consistency.RepairAction{}
+	for _, a := range *repair {
+		action := consistency.RepairAction(strings.TrimSpace(a))
+		if !slices.Contains(consistency.RepairActions, action) {
+			slog.Error("Invalid --repair action.", "action", a, "valid", consistency.RepairActions)
+			os.Exit(2)
+		}
+		repairActions = append(repairActions, action)
+	}
+
+	return Opts{
+		dataDir:    absDataDir,
+		isTmpDir:   absDataDir == tmpDir,
+		reportPath: *reportPath,
+		repair:     repairActions,
+		config:     cfg,
+	}
+}
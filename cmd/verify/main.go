@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"path"
+
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/consistency"
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/constants"
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/logger"
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/parser"
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/schema"
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/writer"
+)
+
+// max number of validation errors logged for each invalid file
+const maxLoggedErrors = 5
+
+type Report struct {
+	Schemas     schema.TreeReport  `json:"schemas"`
+	Consistency consistency.Report `json:"consistency"`
+}
+
+func main() {
+	slog.SetDefault(logger.GetDefaultLogger())
+	opts := ParseOpts()
+	slog.SetDefault(logger.NewLogger(opts.config.Log))
+
+	outputDir := path.Join(opts.dataDir, constants.OutputBaseFolder) // abs path
+	slog.Info("argv validation", "data_dir", opts.dataDir, "output_dir", outputDir)
+
+	report := Report{}
+	ok := true
+
+	schemasReport, err := verifySchemas(outputDir)
+	if err != nil {
+		slog.Error("could not validate output tree against schemas", "error", err)
+		os.Exit(1)
+	}
+	report.Schemas = schemasReport
+	ok = ok && schemasReport.Ok()
+
+	consistencyReport, err := verifyConsistency(opts)
+	if err != nil {
+		slog.Error("could not check data dir consistency", "error", err)
+		os.Exit(1)
+	}
+	report.Consistency = consistencyReport
+	ok = ok && consistencyReport.Ok()
+
+	if opts.reportPath != "" {
+		w := writer.NewWriter[Report](path.Dir(opts.reportPath))
+		if err := w.JsonWrite(path.Base(opts.reportPath), report, true); err != nil {
+			slog.Error("could not write verify report", "path", opts.reportPath, "error", err)
+		} else {
+			slog.Info("verify report written", "path", opts.reportPath)
+		}
+	}
+
+	if !ok {
+		os.Exit(1)
+	}
+}
+
+func verifyConsistency(opts Opts) (consistency.Report, error) {
+	checker := consistency.NewChecker(opts.dataDir)
+	report, err := checker.Check()
+	if err != nil {
+		return report, err
+	}
+
+	for _, i := range report.Issues {
+		slog.Warn("[consistency] issue", "kind", i.Kind, "ranking_id", i.RankingId, "path", i.Path, "link", i.Link, "message", i.Message)
+	}
+	slog.Info("[consistency] check finished", "issues", len(report.Issues), "counts", report.Counts)
+
+	if len(opts.repair) == 0 || report.Ok() {
+		return report, nil
+	}
+
+	slog.Info("[consistency] START repair", "actions", opts.repair)
+	if err := checker.Repair(report, opts.repair, opts.config.Output); err != nil {
+		slog.Error("[consistency] some repairs failed", "error", err)
+	}
+
+	// check again, so the report (and the exit code) reflects the repaired data dir
+	report, err = consistency.NewChecker(opts.dataDir).Check()
+	slog.Info("[consistency] END repair", "remaining_issues", len(report.Issues), "counts", report.Counts)
+	return report, err
+}
+
+func verifySchemas(outputDir string) (schema.TreeReport, error) {
+	entries := parser.OutputSchemas()
+	checkPublishedSchemas(path.Join(outputDir, constants.OutputSchemasFolder), entries)
+
+	report, err := schema.ValidateTree(outputDir, entries)
+	if err != nil {
+		return report, err
+	}
+
+	for _, fr := range report.Invalid {
+		if fr.Err != "" {
+			slog.Error("[schemas] could not validate file", "path", fr.Path, "schema", fr.Schema, "error", fr.Err)
+			continue
+		}
+
+		slog.Error("[schemas] file does not match schema", "path", fr.Path, "schema", fr.Schema, "errors", len(fr.Errors))
+		for _, e := range fr.Errors[:min(len(fr.Errors), maxLoggedErrors)] {
+			slog.Error("[schemas] validation error", "path", fr.Path, "json_path", e.Path, "message", e.Message)
+		}
+	}
+
+	for _, p := range report.Unmatched {
+		slog.Warn("[schemas] file without a known schema", "path", p)
+	}
+
+	slog.Info("[schemas] validation finished", "files", report.Files, "valid", report.Valid, "invalid", len(report.Invalid), "unmatched", len(report.Unmatched))
+	return report, nil
+}
+
+// checkPublishedSchemas warns if the schemas published in the output folder
+// are different from the ones generated by this version of the parser
+func checkPublishedSchemas(schemasDir string, entries []schema.Entry) {
+	for _, e := range entries {
+		if e.Schema == nil {
+			continue
+		}
+
+		fn := e.Name + ".schema.json"
+		published, err := os.ReadFile(path.Join(schemasDir, fn))
+		if err != nil {
+			slog.Warn("[schemas] published schema not found, run the parser to publish it", "filename", fn)
+			continue
+		}
+
+		generated, err := e.Schema.Json()
+		if err != nil {
+			slog.Error("[schemas] could not encode generated schema", "name", e.Name, "error", err)
+			continue
+		}
+
+		if !bytes.Equal(bytes.TrimSpace(published), bytes.TrimSpace(generated)) {
+			slog.Warn("[schemas] published schema is outdated, run the parser to update it", "filename", fn)
+		}
+	}
+}
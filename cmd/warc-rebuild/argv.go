@@ -0,0 +1,96 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/config"
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/constants"
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/utils"
+	"github.com/lmittmann/tint"
+	"github.com/pborman/getopt/v2"
+)
+
+type Opts struct {
+	dataDir  string
+	isTmpDir bool
+	warcDir  string
+	outDir   string
+	force    bool
+	config   config.Config
+}
+
+func ParseOpts() Opts {
+	tmpDir, _ := utils.TmpDirectory() // we don't care if err
+
+	// definition
+	help := getopt.BoolLong("help", 'h', "Shows the help menu")
+	configPath := getopt.StringLong("config", 'c', "", "Path of the config file (yaml or toml). Defaults to RANKINGS_CONFIG env, if set")
+	dataDir := getopt.StringLong("data-dir", 'd', tmpDir, "Path of the data folder (containing html, json, ...). Defaults to tmp directory")
+	warcDir := getopt.StringLong("warc-dir", 'w', "", "Folder of the WARC files. Defaults to <data-dir>/"+constants.OutputWarcFolder)
+	outDir := getopt.StringLong("out", 'o', "", "Folder where the html tree is rebuilt. Defaults to <data-dir>/"+constants.OutputHtmlFolder)
+	force := getopt.BoolLong("force", 'f', "Overwrite the rankings already in the html tree")
+
+	// parsing
+	getopt.Parse()
+
+	if *help {
+		getopt.Usage()
+		os.Exit(0)
+	}
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		slog.Error("Could not load config.", "error", err)
+		os.Exit(2)
+	}
+
+	// flags override config file and env
+	if getopt.IsSet("data-dir") || cfg.DataDir == "" {
+		cfg.DataDir = *dataDir
+	}
+
+	absDataDir, err := filepath.Abs(cfg.DataDir)
+	if err != nil {
+		tint.Err(err)
+		os.Exit(1)
+	}
+	cfg.DataDir = absDataDir
+
+	dataDirExists, err := utils.DoFolderExists(absDataDir)
+	if !dataDirExists {
+		slog.Error("You must set the --data-dir flag to an existing directory.")
+		os.Exit(2)
+	}
+	if err != nil {
+		tint.Err(err)
+		os.Exit(1)
+	}
+
+	absWarcDir := path.Join(absDataDir, constants.OutputWarcFolder)
+	if *warcDir != "" {
+		if absWarcDir, err = filepath.Abs(*warcDir); err != nil {
+			tint.Err(err)
+			os.Exit(1)
+		}
+	}
+
+	absOutDir := path.Join(absDataDir, constants.OutputHtmlFolder)
+	if *outDir != "" {
+		if absOutDir, err = filepath.Abs(*outDir); err != nil {
+			tint.Err(err)
+			os.Exit(1)
+		}
+	}
+
+	return Opts{
+		dataDir:  absDataDir,
+		isTmpDir: absDataDir == tmpDir,
+		warcDir:  absWarcDir,
+		outDir:   absOutDir,
+		force:    *force,
+		config:   cfg,
+	}
+}
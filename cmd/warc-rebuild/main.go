@@ -0,0 +1,62 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+	"path"
+	"slices"
+
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/logger"
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/scraper"
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/utils"
+)
+
+func main() {
+	slog.SetDefault(logger.GetDefaultLogger())
+	opts := ParseOpts()
+	slog.SetDefault(logger.NewLogger(opts.config.Log))
+	slog.Info("argv validation", "data_dir", opts.dataDir, "warc_dir", opts.warcDir, "out", opts.outDir)
+
+	responses, err := utils.ReadWarcResponses(opts.warcDir)
+	if err != nil {
+		slog.Error("could not read WARC files", "error", err)
+		os.Exit(1)
+	}
+
+	links := scraper.ArchivedRankingLinks(responses, opts.config.Urls.RisultatiAmmissioneDomain)
+	slog.Info("[warc-rebuild] rankings found in the archive", "responses", len(responses), "rankings", len(links))
+
+	if !opts.force {
+		links = slices.DeleteFunc(links, func(link string) bool {
+			id, err := scraper.RankingIdFromLink(link)
+			if err != nil {
+				return true
+			}
+			exists, _ := utils.DoFolderExists(path.Join(opts.outDir, id))
+			if exists {
+				slog.Info("[warc-rebuild] ranking already in the html tree, skipping. Use -f to overwrite it", "id", id)
+			}
+			return exists
+		})
+	}
+
+	// every page is served from the archive, nothing is downloaded
+	utils.ConfigureHttpReplay(responses)
+
+	rebuilt := 0
+	for _, r := range scraper.DownloadRankings(links) {
+		if r.PageCount == 0 {
+			slog.Error("[warc-rebuild] ranking main page not archived", "link", r.Url.String())
+			continue
+		}
+
+		if err := r.Save(opts.outDir); err != nil {
+			slog.Error("[warc-rebuild] could not save ranking html", "id", r.Id, "error", err)
+			os.Exit(1)
+		}
+		slog.Info("[warc-rebuild] ranking rebuilt", "id", r.Id, "pages", r.PageCount)
+		rebuilt++
+	}
+
+	slog.Info("[warc-rebuild] done", "rebuilt", rebuilt)
+}
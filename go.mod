@@ -5,9 +5,11 @@ go 1.23.2
 require github.com/lmittmann/tint v1.0.5
 
 require (
+	github.com/BurntSushi/toml v1.6.0
 	github.com/PuerkitoBio/goquery v1.10.0
 	github.com/mattn/go-isatty v0.0.20
 	github.com/pborman/getopt/v2 v2.1.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -0,0 +1,161 @@
+package audit
+
+import (
+	"cmp"
+	"log/slog"
+	"slices"
+
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/parser"
+)
+
+// FlaggedRow is a row whose quasi-identifiers are shared by less than k rows of the same ranking
+type FlaggedRow struct {
+	Position  uint16 `json:"position"`
+	StudentId string `json:"studentId,omitempty"`
+	ClassSize int    `json:"classSize"`
+}
+
+type RankingRisk struct {
+	Id      string `json:"id"`
+	Rows    int    `json:"rows"`
+	Classes int    `json:"classes"` // number of distinct quasi-identifier combinations
+	Unique  int    `json:"unique"`  // rows with a unique combination
+	BelowK  int    `json:"belowK"`  // rows whose combination is shared by less than k rows
+
+	// expected fraction of re-identified rows, if an attacker knows the quasi-identifiers of everyone
+	AvgRisk float64 `json:"avgRisk"`
+	// re-identification probability of the most exposed row (1 / smallest class size)
+	MaxRisk float64 `json:"maxRisk"`
+
+	Flagged []FlaggedRow `json:"flagged"`
+
+	// generalisation level used by the redacted output (see generalise.go)
+	RedactionLevel int `json:"redactionLevel"`
+	// rows still below k at RedactionLevel
+	Residual int `json:"residual"`
+}
+
+type Report struct {
+	K        int           `json:"k"`
+	Rows     int           `json:"rows"`
+	Unique   int           `json:"unique"`
+	BelowK   int           `json:"belowK"`
+	Rankings []RankingRisk `json:"rankings"`
+}
+
+func (r *Report) Ok() bool {
+	return r.BelowK == 0
+}
+
+// Auditor checks the k-anonymity of the published rankings on their quasi-identifiers:
+// birth date, result, sections results, course and location
+type Auditor struct {
+	outDir   string
+	k        int
+	rankings []parser.Ranking
+	levels   map[string]int // ranking id -> redaction level
+}
+
+func NewAuditor(absOutDir string, k int) *Auditor {
+	return &Auditor{outDir: absOutDir, k: k, levels: map[string]int{}}
+}
+
+func (a *Auditor) Run() (Report, error) {
+	report := Report{K: a.k, Rankings: []RankingRisk{}}
+	if err := a.load(); err != nil {
+		return report, err
+	}
+
+	for _, r := range a.rankings {
+		risk := a.assess(r)
+		report.Rows += risk.Rows
+		report.Unique += risk.Unique
+		report.BelowK += risk.BelowK
+		report.Rankings = append(report.Rankings, risk)
+	}
+
+	return report, nil
+}
+
+func (a *Auditor) load() error {
+	rankings, err := parser.LoadRankings(a.outDir)
+	if err != nil {
+		return err
+	}
+
+	a.rankings = rankings
+	slog.Info("[privacy-audit] loaded rankings", "count", len(a.rankings))
+	return nil
+}
+
+func (a *Auditor) assess(r parser.Ranking) RankingRisk {
+	risk := RankingRisk{Id: r.Id, Rows: len(r.Rows), Flagged: []FlaggedRow{}}
+	if len(r.Rows) == 0 {
+		return risk
+	}
+
+	sizes := classSizes(r.Rows, 0, r.Year)
+	risk.Classes = countClasses(sizes)
+	minSize := len(r.Rows)
+	for i, row := range r.Rows {
+		size := sizes[i]
+		risk.AvgRisk += 1 / float64(size)
+		minSize = min(minSize, size)
+
+		if size == 1 {
+			risk.Unique++
+		}
+		if size < a.k {
+			risk.BelowK++
+			risk.Flagged = append(risk.Flagged, FlaggedRow{Position: row.Position, StudentId: row.Id, ClassSize: size})
+		}
+	}
+	risk.AvgRisk /= float64(len(r.Rows))
+	risk.MaxRisk = 1 / float64(minSize)
+
+	slices.SortStableFunc(risk.Flagged, func(a, b FlaggedRow) int { return cmp.Compare(a.Position, b.Position) })
+
+	// lowest level which makes the ranking k-anonymous
+	risk.RedactionLevel, risk.Residual = maxLevel, 0
+	for level := 0; level <= maxLevel; level++ {
+		residual := 0
+		for _, size := range classSizes(r.Rows, level, r.Year) {
+			if size < a.k {
+				residual++
+			}
+		}
+
+		if residual == 0 || level == maxLevel {
+			risk.RedactionLevel, risk.Residual = level, residual
+			break
+		}
+	}
+	a.levels[r.Id] = risk.RedactionLevel
+
+	return risk
+}
+
+// classSizes returns, for each row, how many rows share its quasi-identifiers at the given generalisation level
+func classSizes(rows []parser.StudentRow, level int, rankingYear uint16) []int {
+	keys := make([]string, len(rows))
+	counts := map[string]int{}
+	for i, row := range rows {
+		generalise(&row, level, rankingYear) // row is a copy
+		keys[i] = quasiIdentifiers(row)
+		counts[keys[i]]++
+	}
+
+	sizes := make([]int, len(rows))
+	for i, key := range keys {
+		sizes[i] = counts[key]
+	}
+	return sizes
+}
+
+func countClasses(sizes []int) int {
+	classes := 0.0
+	for _, size := range sizes {
+		classes += 1 / float64(size)
+	}
+	return int(classes + 0.5)
+}
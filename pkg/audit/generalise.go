@@ -0,0 +1,79 @@
+package audit
+
+import (
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/parser"
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/privacy"
+)
+
+// Generalisation levels, each one includes the previous ones:
+//  0. published data as is
+//  1. birth date -> year of birth, sections results dropped
+//  2. year of birth -> age group, result rounded down to an integer
+//  3. birth data dropped, result rounded down to a multiple of 5
+//
+// Course and location are never generalised, since they are the point of the ranking.
+const maxLevel = 3
+
+var levelBirthDatePolicies = []privacy.BirthDatePolicy{
+	privacy.BirthDateKeep,
+	privacy.BirthDateYearOnly,
+	privacy.BirthDateAgeBucket,
+	privacy.BirthDateDrop,
+}
+
+// generalise must not modify the maps of row, since it is used on shallow copies
+func generalise(row *parser.StudentRow, level int, rankingYear uint16) {
+	level = min(level, maxLevel)
+	row.ApplyBirthDatePolicy(levelBirthDatePolicies[level], rankingYear)
+
+	if level >= 1 {
+		row.SectionsResults = nil
+	}
+
+	switch {
+	case level >= 3:
+		row.Result = float32(math.Floor(float64(row.Result)/5) * 5)
+	case level >= 2:
+		row.Result = float32(math.Floor(float64(row.Result)))
+	}
+}
+
+// quasiIdentifiers returns the key of the row's equivalence class
+func quasiIdentifiers(row parser.StudentRow) string {
+	birth := row.AgeGroup
+	if row.BirthYear != 0 {
+		birth = fmt.Sprint(row.BirthYear)
+	}
+	if row.BirthDate != nil {
+		birth = row.BirthDate.String()
+	}
+
+	sections := make([]string, 0, len(row.SectionsResults))
+	for _, name := range slices.Sorted(maps.Keys(row.SectionsResults)) {
+		sections = append(sections, fmt.Sprintf("%s=%.2f", name, row.SectionsResults[name]))
+	}
+
+	course, location := enrolledCourse(row)
+	return strings.Join([]string{birth, fmt.Sprintf("%.2f", row.Result), strings.Join(sections, ","), course, location}, "|")
+}
+
+// enrolledCourse returns the course the student can enroll in, or the first one listed
+func enrolledCourse(row parser.StudentRow) (string, string) {
+	if len(row.Courses) == 0 {
+		return "", ""
+	}
+
+	for _, c := range row.Courses {
+		if c.CanEnroll {
+			return c.Title, c.Location
+		}
+	}
+
+	return row.Courses[0].Title, row.Courses[0].Location
+}
@@ -0,0 +1,85 @@
+package audit
+
+import (
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/constants"
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/parser"
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/writer"
+)
+
+// Redact writes a copy of the output tree to absDestDir, where every ranking
+// is generalised at the level found by Run. Stats and diffs are rebuilt from the
+// redacted rankings, the student id hash index is omitted since it links the
+// same student across rankings. Other files are copied as they are.
+func (a *Auditor) Redact(absDestDir string, indent bool) error {
+	if rel, err := filepath.Rel(a.outDir, absDestDir); err == nil && !strings.HasPrefix(rel, "..") {
+		return fmt.Errorf("the redacted output must be outside of the output folder %s", a.outDir)
+	}
+
+	// written below from the redacted rankings, or omitted
+	skipFolders := []string{constants.OutputParsedRankingsFolder, constants.OutputRankingsStatsFolder, constants.OutputDiffsFolder}
+	skipFiles := []string{
+		path.Join(constants.OutputIndexesFolder, constants.OutputIndexDiffsFilename),
+		path.Join(constants.OutputIndexesFolder, constants.OutputIndexByStudentIdHashFilename),
+	}
+
+	err := filepath.WalkDir(a.outDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		rel, _ := filepath.Rel(a.outDir, p)
+		rel = filepath.ToSlash(rel)
+		if slices.Contains(skipFolders, path.Dir(rel)) || slices.Contains(skipFiles, rel) {
+			return nil
+		}
+
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+
+		w := writer.NewWriter[[]byte](path.Join(absDestDir, path.Dir(rel)))
+		return w.Write(path.Base(rel), data)
+	})
+	if err != nil {
+		return fmt.Errorf("could not copy output tree: %w", err)
+	}
+
+	w := writer.NewWriter[parser.Ranking](path.Join(absDestDir, constants.OutputParsedRankingsFolder))
+	statsWriter := writer.NewWriter[*parser.RankingStats](path.Join(absDestDir, constants.OutputRankingsStatsFolder))
+	redacted := make([]parser.Ranking, 0, len(a.rankings))
+	for _, r := range a.rankings {
+		level := a.levels[r.Id]
+		rows := make([]parser.StudentRow, len(r.Rows))
+		for i, row := range r.Rows {
+			generalise(&row, level, r.Year)
+			rows[i] = row
+		}
+		r.Rows = rows
+
+		if err := w.JsonWrite(r.Id+".json", r, indent); err != nil {
+			return fmt.Errorf("could not write redacted ranking %s: %w", r.Id, err)
+		}
+		if err := statsWriter.JsonWrite(r.Id+".json", parser.NewRankingStats(&r), false); err != nil {
+			return fmt.Errorf("could not write stats of redacted ranking %s: %w", r.Id, err)
+		}
+		redacted = append(redacted, r)
+
+		slog.Debug("[privacy-audit] redacted ranking", "id", r.Id, "level", level)
+	}
+
+	if err := parser.WriteDiffs(absDestDir, parser.DiffConsecutivePhases(redacted), indent); err != nil {
+		return fmt.Errorf("could not write diffs of redacted rankings: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,307 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/constants"
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/privacy"
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the effective configuration of every command.
+// Values are resolved with the following precedence (last wins):
+// defaults -> config file (yaml/toml) -> env variables -> command line flags
+type Config struct {
+	// empty means tmp directory
+	DataDir    string           `yaml:"dataDir" toml:"dataDir"`
+	Urls       UrlsConfig       `yaml:"urls" toml:"urls"`
+	Http       HttpConfig       `yaml:"http" toml:"http"`
+	Bruteforce BruteforceConfig `yaml:"bruteforce" toml:"bruteforce"`
+	Output     OutputConfig     `yaml:"output" toml:"output"`
+	Log        LogConfig        `yaml:"log" toml:"log"`
+	Notify     NotifyConfig     `yaml:"notify" toml:"notify"`
+	Archive    ArchiveConfig    `yaml:"archive" toml:"archive"`
+}
+
+type UrlsConfig struct {
+	RisultatiAmmissioneDomain string `yaml:"risultatiAmmissioneDomain" toml:"risultatiAmmissioneDomain"`
+	AvvisiFuturiStudenti      string `yaml:"avvisiFuturiStudenti" toml:"avvisiFuturiStudenti"`
+	// english news page, searched like the avvisi page. Empty disables the news-en link source
+	NewsEn string `yaml:"newsEn" toml:"newsEn"`
+	// admission pages of the schools, searched for rankings links. Empty disables the school-pages link source
+	SchoolAdmissionPages []string `yaml:"schoolAdmissionPages" toml:"schoolAdmissionPages"`
+	// course pages used to obtain each school's manifesto link
+	SchoolCoursePages []string `yaml:"schoolCoursePages" toml:"schoolCoursePages"`
+	// list of every course page, crawled if no school course page works
+	CourseList    string `yaml:"courseList" toml:"courseList"`
+	GithubRawData string `yaml:"githubRawData" toml:"githubRawData"`
+}
+
+type HttpConfig struct {
+	UserAgent string        `yaml:"userAgent" toml:"userAgent"`
+	Timeout   time.Duration `yaml:"timeout" toml:"timeout"`
+	// retries after 429, 503 or a timeout, waiting Retry-After or an exponential backoff
+	MaxRetries  int           `yaml:"maxRetries" toml:"maxRetries"`
+	BackoffBase time.Duration `yaml:"backoffBase" toml:"backoffBase"`
+	MaxBackoff  time.Duration `yaml:"maxBackoff" toml:"maxBackoff"`
+	// max requests per host per run, not counting the bruteforce ones (see BruteforceConfig.Budget), 0 = unlimited
+	HostBudget int             `yaml:"hostBudget" toml:"hostBudget"`
+	Robots     bool            `yaml:"robots" toml:"robots"`
+	Cache      HttpCacheConfig `yaml:"cache" toml:"cache"`
+}
+
+// HttpCacheConfig configures the on-disk cache of the scraped pages
+type HttpCacheConfig struct {
+	Enabled bool `yaml:"enabled" toml:"enabled"`
+	// a cached page younger than the TTL is used without any request,
+	// an older one is revalidated with a conditional GET (ETag / Last-Modified)
+	TTL   time.Duration         `yaml:"ttl" toml:"ttl"`
+	Rules []HttpCacheRuleConfig `yaml:"rules" toml:"rules"`
+}
+
+// HttpCacheRuleConfig sets the TTL of the urls matching a regexp, the first matching rule wins
+type HttpCacheRuleConfig struct {
+	Pattern string        `yaml:"pattern" toml:"pattern"`
+	TTL     time.Duration `yaml:"ttl" toml:"ttl"`
+}
+
+// Options returns the options of the HTTP politeness layer. The cache is
+// saved in absCacheDir, if enabled (empty absCacheDir disables it too)
+func (c HttpConfig) Options(absCacheDir string) utils.HttpOptions {
+	rules := make([]utils.HttpCacheRule, 0, len(c.Cache.Rules))
+	for _, r := range c.Cache.Rules {
+		rules = append(rules, utils.HttpCacheRule{Pattern: r.Pattern, TTL: r.TTL})
+	}
+	if !c.Cache.Enabled {
+		absCacheDir = ""
+	}
+
+	return utils.HttpOptions{
+		UserAgent:   c.UserAgent,
+		Timeout:     c.Timeout,
+		MaxRetries:  c.MaxRetries,
+		BackoffBase: c.BackoffBase,
+		MaxBackoff:  c.MaxBackoff,
+		HostBudget:  c.HostBudget,
+		Robots:      c.Robots,
+		CacheDir:    absCacheDir,
+		CacheTTL:    c.Cache.TTL,
+		CacheRules:  rules,
+	}
+}
+
+type BruteforceConfig struct {
+	Workers int           `yaml:"workers" toml:"workers"`
+	Rps     int           `yaml:"rps" toml:"rps"` // 0 = unlimited
+	Timeout time.Duration `yaml:"timeout" toml:"timeout"`
+	// stop testing a phase ID after the first hit, and skip the ones already saved for the year
+	EarlyStop bool `yaml:"earlyStop" toml:"earlyStop"`
+	// max requests of the bruteforce per run, 0 = unlimited. The next run resumes a stopped bruteforce
+	Budget int `yaml:"budget" toml:"budget"`
+}
+
+type OutputConfig struct {
+	IndentRankings  bool `yaml:"indentRankings" toml:"indentRankings"`
+	IndentIndexes   bool `yaml:"indentIndexes" toml:"indentIndexes"` // also diffs, seat flows, admission models and parse report
+	IndentManifesti bool `yaml:"indentManifesti" toml:"indentManifesti"`
+	// applied to every output containing student rows
+	BirthDatePolicy privacy.BirthDatePolicy `yaml:"birthDatePolicy" toml:"birthDatePolicy"`
+}
+
+type LogConfig struct {
+	Level     string `yaml:"level" toml:"level"`
+	AddSource bool   `yaml:"addSource" toml:"addSource"`
+	NoColor   bool   `yaml:"noColor" toml:"noColor"`
+}
+
+type ArchiveConfig struct {
+	// write every page fetched by the scraper in WARC files, in <dataDir>/warc
+	Warc bool `yaml:"warc" toml:"warc"`
+}
+
+type NotifyConfig struct {
+	WebhookUrl   string   `yaml:"webhookUrl" toml:"webhookUrl"`
+	TelegramChat string   `yaml:"telegramChat" toml:"telegramChat"`
+	TelegramTok  string   `yaml:"telegramToken" toml:"telegramToken"`
+	SmtpHost     string   `yaml:"smtpHost" toml:"smtpHost"`
+	SmtpUser     string   `yaml:"smtpUsername" toml:"smtpUsername"`
+	SmtpPassword string   `yaml:"smtpPassword" toml:"smtpPassword"`
+	EmailFrom    string   `yaml:"emailFrom" toml:"emailFrom"`
+	EmailTo      []string `yaml:"emailTo" toml:"emailTo"`
+	Command      string   `yaml:"command" toml:"command"`
+	Retries      int      `yaml:"retries" toml:"retries"`
+	DryRun       bool     `yaml:"dryRun" toml:"dryRun"`
+}
+
+func Default() Config {
+	return Config{
+		DataDir: "",
+		Urls: UrlsConfig{
+			RisultatiAmmissioneDomain: constants.WebPolimiRisultatiAmmissioneDomainName,
+			AvvisiFuturiStudenti:      constants.WebPolimiAvvisiFuturiStudentiUrl,
+			SchoolCoursePages:         []string{constants.WebPolimiDesignUrl, constants.WebPolimiArchUrbUrl, constants.WebPolimiIngCivUrl, constants.WebPolimiIngInfIndUrl},
+			CourseList:                constants.WebPolimiCourseListUrl,
+			GithubRawData:             constants.WebGithubMainRawDataUrl,
+		},
+		Http: HttpConfig{
+			UserAgent:   constants.HttpDefaultUserAgent,
+			Timeout:     30 * time.Second,
+			MaxRetries:  3,
+			BackoffBase: time.Second,
+			MaxBackoff:  time.Minute,
+			HostBudget:  5000, // enough for the routine runs, the bruteforce has its own budget
+			Robots:      true,
+			Cache: HttpCacheConfig{
+				Enabled: true,
+				TTL:     0, // always revalidate
+				Rules:   []HttpCacheRuleConfig{},
+			},
+		},
+		Bruteforce: BruteforceConfig{
+			Workers:   10,
+			Rps:       20,
+			Timeout:   10 * time.Second,
+			EarlyStop: true,
+			Budget:    20000, // about 17 minutes at 20 rps
+		},
+		Output: OutputConfig{
+			IndentRankings:  true,
+			IndentIndexes:   true,
+			IndentManifesti: false,
+			BirthDatePolicy: privacy.BirthDateKeep,
+		},
+		Log: LogConfig{
+			Level:     "info",
+			AddSource: true,
+			NoColor:   false,
+		},
+		Notify: NotifyConfig{
+			Retries: 3,
+		},
+	}
+}
+
+// Load returns the default config, overridden by the file at filePath (if not empty)
+// and by env variables. Flags must be applied by the caller.
+// If filePath is empty, the RANKINGS_CONFIG env variable is used as path.
+func Load(filePath string) (Config, error) {
+	cfg := Default()
+
+	if filePath == "" {
+		filePath = os.Getenv(EnvConfigPath)
+	}
+
+	if filePath != "" {
+		if err := cfg.loadFile(filePath); err != nil {
+			return cfg, err
+		}
+	}
+
+	if err := cfg.applyEnv(); err != nil {
+		return cfg, err
+	}
+
+	return cfg, cfg.Validate()
+}
+
+func (c *Config) loadFile(filePath string) error {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return fmt.Errorf("could not read config file %s: %w", filePath, err)
+	}
+
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, c)
+	case ".toml":
+		err = toml.Unmarshal(data, c)
+	default:
+		return fmt.Errorf("unsupported config file extension '%s', use .yaml, .yml or .toml", filepath.Ext(filePath))
+	}
+
+	if err != nil {
+		return fmt.Errorf("could not parse config file %s: %w", filePath, err)
+	}
+
+	return nil
+}
+
+func (c *Config) Validate() error {
+	if c.Bruteforce.Workers <= 0 {
+		return fmt.Errorf("bruteforce.workers must be greater than 0, got %d", c.Bruteforce.Workers)
+	}
+
+	if c.Bruteforce.Rps < 0 {
+		return fmt.Errorf("bruteforce.rps must be 0 (unlimited) or greater, got %d", c.Bruteforce.Rps)
+	}
+
+	if c.Bruteforce.Timeout <= 0 || c.Http.Timeout <= 0 {
+		return fmt.Errorf("http.timeout and bruteforce.timeout must be greater than 0")
+	}
+
+	if c.Http.UserAgent == "" {
+		return fmt.Errorf("http.userAgent must not be empty")
+	}
+
+	if c.Http.MaxRetries < 0 || c.Http.HostBudget < 0 || c.Bruteforce.Budget < 0 {
+		return fmt.Errorf("http.maxRetries, http.hostBudget and bruteforce.budget must not be negative")
+	}
+
+	if c.Http.BackoffBase <= 0 || c.Http.MaxBackoff < c.Http.BackoffBase {
+		return fmt.Errorf("http.backoffBase must be greater than 0 and not greater than http.maxBackoff")
+	}
+
+	if c.Http.Cache.TTL < 0 {
+		return fmt.Errorf("http.cache.ttl must not be negative")
+	}
+
+	for _, r := range c.Http.Cache.Rules {
+		if _, err := regexp.Compile(r.Pattern); err != nil {
+			return fmt.Errorf("http.cache.rules: invalid pattern '%s': %w", r.Pattern, err)
+		}
+		if r.TTL < 0 {
+			return fmt.Errorf("http.cache.rules: ttl of '%s' must not be negative", r.Pattern)
+		}
+	}
+
+	policy, err := privacy.ParseBirthDatePolicy(string(c.Output.BirthDatePolicy))
+	if err != nil {
+		return fmt.Errorf("output.birthDatePolicy: %w", err)
+	}
+	c.Output.BirthDatePolicy = policy
+
+	if c.Notify.Retries < 0 {
+		return fmt.Errorf("notify.retries must not be negative, got %d", c.Notify.Retries)
+	}
+
+	return nil
+}
+
+// Redacted returns a copy of the config without secrets, useful to print it
+func (c Config) Redacted() Config {
+	redact := func(s string) string {
+		if s == "" {
+			return s
+		}
+		return "<redacted>"
+	}
+
+	c.Notify.TelegramTok = redact(c.Notify.TelegramTok)
+	c.Notify.SmtpPassword = redact(c.Notify.SmtpPassword)
+	c.Urls.SchoolCoursePages = append([]string{}, c.Urls.SchoolCoursePages...)
+	c.Urls.SchoolAdmissionPages = append([]string{}, c.Urls.SchoolAdmissionPages...)
+	c.Http.Cache.Rules = append([]HttpCacheRuleConfig{}, c.Http.Cache.Rules...)
+	c.Notify.EmailTo = append([]string{}, c.Notify.EmailTo...)
+	return c
+}
+
+func (c Config) ToYaml() ([]byte, error) {
+	return yaml.Marshal(c)
+}
@@ -0,0 +1,117 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/privacy"
+)
+
+const (
+	EnvConfigPath = "RANKINGS_CONFIG"
+
+	envDataDir           = "RANKINGS_DATA_DIR"
+	envUserAgent         = "RANKINGS_USER_AGENT"
+	envHttpTimeout       = "RANKINGS_HTTP_TIMEOUT"
+	envHttpHostBudget    = "RANKINGS_HTTP_HOST_BUDGET"
+	envBruteforceWorkers = "RANKINGS_BRUTEFORCE_WORKERS"
+	envBruteforceRps     = "RANKINGS_BRUTEFORCE_RPS"
+	envBruteforceTimeout = "RANKINGS_BRUTEFORCE_TIMEOUT"
+	envBruteforceBudget  = "RANKINGS_BRUTEFORCE_BUDGET"
+	envBirthDatePolicy   = "RANKINGS_BIRTH_DATE_POLICY"
+	envLogLevel          = "LOG_LEVEL" // kept for backward compatibility
+	envLogSource         = "RANKINGS_LOG_SOURCE"
+	envLogNoColor        = "NO_COLOR"
+	envTelegramToken     = "TELEGRAM_BOT_TOKEN"
+	envSmtpUsername      = "SMTP_USERNAME"
+	envSmtpPassword      = "SMTP_PASSWORD"
+)
+
+func (c *Config) applyEnv() error {
+	errs := make([]string, 0)
+	collect := func(err error) {
+		if err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	envString(envDataDir, &c.DataDir)
+	envString(envUserAgent, &c.Http.UserAgent)
+	collect(envDuration(envHttpTimeout, &c.Http.Timeout))
+	collect(envInt(envHttpHostBudget, &c.Http.HostBudget))
+	collect(envInt(envBruteforceWorkers, &c.Bruteforce.Workers))
+	collect(envInt(envBruteforceRps, &c.Bruteforce.Rps))
+	collect(envDuration(envBruteforceTimeout, &c.Bruteforce.Timeout))
+	collect(envInt(envBruteforceBudget, &c.Bruteforce.Budget))
+	if v, ok := os.LookupEnv(envBirthDatePolicy); ok {
+		c.Output.BirthDatePolicy = privacy.BirthDatePolicy(v) // checked by Validate
+	}
+	envString(envLogLevel, &c.Log.Level)
+	collect(envBool(envLogSource, &c.Log.AddSource))
+	if _, ok := os.LookupEnv(envLogNoColor); ok {
+		c.Log.NoColor = true // https://no-color.org
+	}
+	envString(envTelegramToken, &c.Notify.TelegramTok)
+	envString(envSmtpUsername, &c.Notify.SmtpUser)
+	envString(envSmtpPassword, &c.Notify.SmtpPassword)
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid env variable(s):\n%s", strings.Join(errs, "\n"))
+	}
+
+	return nil
+}
+
+func envString(key string, out *string) {
+	if v, ok := os.LookupEnv(key); ok {
+		*out = v
+	}
+}
+
+func envInt(key string, out *int) error {
+	v, ok := os.LookupEnv(key)
+	if !ok {
+		return nil
+	}
+
+	parsed, err := strconv.Atoi(v)
+	if err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+
+	*out = parsed
+	return nil
+}
+
+func envBool(key string, out *bool) error {
+	v, ok := os.LookupEnv(key)
+	if !ok {
+		return nil
+	}
+
+	parsed, err := strconv.ParseBool(v)
+	if err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+
+	*out = parsed
+	return nil
+}
+
+func envDuration(key string, out *time.Duration) error {
+	v, ok := os.LookupEnv(key)
+	if !ok {
+		return nil
+	}
+
+	parsed, err := time.ParseDuration(v)
+	if err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+
+	*out = parsed
+	return nil
+}
@@ -0,0 +1,227 @@
+package consistency
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/constants"
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/scraper"
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/utils"
+)
+
+type IssueKind string
+
+const (
+	// a link in scraped.json without its html/<id> folder
+	IssueMissingHtml IssueKind = "missing_html"
+	// an html/<id> folder not linked by scraped.json
+	IssueOrphanHtml IssueKind = "orphan_html"
+	// a link in broken.json which has an html/<id> folder
+	IssueBrokenWithHtml IssueKind = "broken_with_html"
+	// a bruteforce valid link which is neither scraped nor broken
+	IssueNotDownloaded IssueKind = "not_downloaded"
+	// an html/<id> folder without index.html
+	IssueMissingIndex IssueKind = "missing_index"
+	// an html/<id> folder (or one of its table folders) without html pages
+	IssueEmptyFolder IssueKind = "empty_folder"
+	// the number of saved pages does not match what the index page links
+	IssuePageCountMismatch IssueKind = "page_count_mismatch"
+	// an output/rankings/<id>.json whose html/<id> folder does not exist
+	IssueOrphanOutput IssueKind = "orphan_output"
+	// an html/<id> folder without output/rankings/<id>.json
+	IssueNotParsed IssueKind = "not_parsed"
+)
+
+type Issue struct {
+	Kind      IssueKind `json:"kind"`
+	RankingId string    `json:"rankingId"`
+	Link      string    `json:"link,omitempty"`
+	Path      string    `json:"path,omitempty"` // relative to the data dir
+	Message   string    `json:"message"`
+}
+
+type Report struct {
+	Issues []Issue           `json:"issues"`
+	Counts map[IssueKind]int `json:"counts"`
+}
+
+func (r *Report) Ok() bool {
+	return len(r.Issues) == 0
+}
+
+func (r *Report) add(i Issue) {
+	r.Issues = append(r.Issues, i)
+	r.Counts[i.Kind]++
+}
+
+func (r *Report) ByKind(kind IssueKind) []Issue {
+	out := make([]Issue, 0)
+	for _, i := range r.Issues {
+		if i.Kind == kind {
+			out = append(out, i)
+		}
+	}
+	return out
+}
+
+// Checker cross-checks links, html and output folders of a data dir
+type Checker struct {
+	dataDir string
+
+	scraped    map[string]string // ranking id -> link
+	broken     map[string]string // ranking id -> link
+	bruteforce map[string]string // ranking id -> link
+	htmlIds    []string
+	outputIds  []string
+}
+
+func NewChecker(absDataDir string) *Checker {
+	return &Checker{
+		dataDir:    absDataDir,
+		scraped:    map[string]string{},
+		broken:     map[string]string{},
+		bruteforce: map[string]string{},
+	}
+}
+
+func (c *Checker) Check() (Report, error) {
+	report := Report{Issues: []Issue{}, Counts: map[IssueKind]int{}}
+	if err := c.load(); err != nil {
+		return report, err
+	}
+
+	for id, link := range c.scraped {
+		if !slices.Contains(c.htmlIds, id) {
+			report.add(Issue{Kind: IssueMissingHtml, RankingId: id, Link: link, Message: "scraped link without html folder"})
+		}
+	}
+
+	for id, link := range c.broken {
+		if slices.Contains(c.htmlIds, id) {
+			report.add(Issue{Kind: IssueBrokenWithHtml, RankingId: id, Link: link, Path: c.htmlRel(id), Message: "broken link with html folder"})
+		}
+	}
+
+	for id, link := range c.bruteforce {
+		_, isScraped := c.scraped[id]
+		_, isBroken := c.broken[id]
+		if !isScraped && !isBroken {
+			report.add(Issue{Kind: IssueNotDownloaded, RankingId: id, Link: link, Message: "bruteforce valid link neither scraped nor broken"})
+		}
+	}
+
+	for _, id := range c.htmlIds {
+		if _, ok := c.scraped[id]; !ok {
+			report.add(Issue{Kind: IssueOrphanHtml, RankingId: id, Path: c.htmlRel(id), Message: "html folder not linked by scraped links"})
+		}
+
+		if !slices.Contains(c.outputIds, id) {
+			report.add(Issue{Kind: IssueNotParsed, RankingId: id, Path: c.htmlRel(id), Message: "html folder without parsed output"})
+		}
+
+		for _, issue := range c.checkHtmlFolder(id) {
+			report.add(issue)
+		}
+	}
+
+	for _, id := range c.outputIds {
+		if !slices.Contains(c.htmlIds, id) {
+			report.add(Issue{Kind: IssueOrphanOutput, RankingId: id, Path: c.outputRel(id), Message: "parsed output whose html folder does not exist"})
+		}
+	}
+
+	slices.SortStableFunc(report.Issues, func(a, b Issue) int {
+		if n := strings.Compare(string(a.Kind), string(b.Kind)); n != 0 {
+			return n
+		}
+		return strings.Compare(a.RankingId, b.RankingId)
+	})
+
+	return report, nil
+}
+
+func (c *Checker) load() error {
+	linksDir := path.Join(c.dataDir, constants.OutputLinksFolder)
+
+	for fn, out := range map[string]map[string]string{
+		constants.OutputScrapedLinksFilename: c.scraped,
+		constants.OutputBrokenLinksFilename:  c.broken,
+	} {
+		links, err := readLinks(path.Join(linksDir, fn))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("could not read %s: %w", fn, err)
+		}
+		addLinks(out, links)
+	}
+
+	bfFiles, _ := filepath.Glob(path.Join(linksDir, constants.OutputBruteForceFolder, "valid_links_*.json"))
+	for _, fp := range bfFiles {
+		links, err := readLinks(fp)
+		if err != nil {
+			return fmt.Errorf("could not read %s: %w", fp, err)
+		}
+		addLinks(c.bruteforce, links)
+	}
+
+	entries, err := utils.GetEntriesInFolder(path.Join(c.dataDir, constants.OutputHtmlFolder))
+	if err != nil {
+		slog.Warn("[consistency] could not read html folder", "error", err)
+	}
+	for _, e := range entries {
+		if e.IsDir() && e.Name() != "style" {
+			c.htmlIds = append(c.htmlIds, e.Name())
+		}
+	}
+
+	entries, err = utils.GetEntriesInFolder(path.Join(c.dataDir, constants.OutputBaseFolder, constants.OutputParsedRankingsFolder))
+	if err != nil {
+		slog.Warn("[consistency] could not read output rankings folder", "error", err)
+	}
+	for _, e := range entries {
+		if id, ok := strings.CutSuffix(e.Name(), ".json"); ok && !e.IsDir() {
+			c.outputIds = append(c.outputIds, id)
+		}
+	}
+
+	slog.Info("[consistency] loaded data dir", "scraped", len(c.scraped), "broken", len(c.broken), "bruteforce", len(c.bruteforce), "html", len(c.htmlIds), "output", len(c.outputIds))
+	return nil
+}
+
+// we don't use writer.Writer here, since it creates missing folders
+// while the checker must not touch the data dir
+func readLinks(fp string) ([]string, error) {
+	data, err := os.ReadFile(fp)
+	if err != nil {
+		return nil, err
+	}
+
+	links := []string{}
+	err = json.Unmarshal(data, &links)
+	return links, err
+}
+
+func addLinks(out map[string]string, links []string) {
+	for _, link := range links {
+		id, err := scraper.RankingIdFromLink(link)
+		if err != nil {
+			slog.Warn("[consistency] could not get ranking id from link", "link", link, "error", err)
+			continue
+		}
+		out[id] = link
+	}
+}
+
+func (c *Checker) htmlRel(id string) string {
+	return path.Join(constants.OutputHtmlFolder, id)
+}
+
+func (c *Checker) outputRel(id string) string {
+	return path.Join(constants.OutputBaseFolder, constants.OutputParsedRankingsFolder, id+".json")
+}
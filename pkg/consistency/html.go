@@ -0,0 +1,138 @@
+package consistency
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/constants"
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/scraper"
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/utils"
+)
+
+var tableFolders = []string{
+	constants.OutputHtmlRanking_ByMeritFolder,
+	constants.OutputHtmlRanking_ByIdFolder,
+	constants.OutputHtmlRanking_ByCourseFolder,
+}
+
+func (c *Checker) checkHtmlFolder(id string) []Issue {
+	issues := make([]Issue, 0)
+	root := path.Join(c.dataDir, constants.OutputHtmlFolder, id)
+	rel := c.htmlRel(id)
+
+	counts := map[string]int{}
+	total := 0
+	for _, folder := range tableFolders {
+		counts[folder] = countHtmlPages(path.Join(root, folder))
+		total += counts[folder]
+	}
+
+	index, err := os.ReadFile(path.Join(root, constants.OutputHtmlRanking_IndexFilename))
+	if err != nil {
+		issues = append(issues, Issue{Kind: IssueMissingIndex, RankingId: id, Path: rel, Message: "html folder without index.html"})
+	}
+
+	if total == 0 {
+		return append(issues, Issue{Kind: IssueEmptyFolder, RankingId: id, Path: rel, Message: "html folder without table pages"})
+	}
+
+	if counts[constants.OutputHtmlRanking_ByMeritFolder] == 0 {
+		issues = append(issues, Issue{Kind: IssueEmptyFolder, RankingId: id, Path: path.Join(rel, constants.OutputHtmlRanking_ByMeritFolder), Message: "ranking without merit table pages"})
+	}
+
+	if index != nil {
+		linked, err := linkedTableFolders(index)
+		if err != nil {
+			issues = append(issues, Issue{Kind: IssueMissingIndex, RankingId: id, Path: rel, Message: fmt.Sprintf("could not parse index.html: %s", err)})
+		}
+
+		for _, folder := range linked {
+			if counts[folder] == 0 && folder != constants.OutputHtmlRanking_ByMeritFolder {
+				issues = append(issues, Issue{Kind: IssueEmptyFolder, RankingId: id, Path: path.Join(rel, folder), Message: "the index page links this table, but no page was saved"})
+				continue
+			}
+
+			issues = append(issues, checkLinkedPages(root, rel, id, folder, counts[folder])...)
+		}
+	}
+
+	// by_merit and by_id list the same students with the same page size,
+	// so they must have the same number of pages
+	meritCount, idCount := counts[constants.OutputHtmlRanking_ByMeritFolder], counts[constants.OutputHtmlRanking_ByIdFolder]
+	if meritCount > 0 && idCount > 0 && meritCount != idCount {
+		issues = append(issues, Issue{Kind: IssuePageCountMismatch, RankingId: id, Path: rel, Message: fmt.Sprintf("by_merit has %d pages, by_id has %d pages", meritCount, idCount)})
+	}
+
+	return issues
+}
+
+// linkedTableFolders returns the table folders linked by the index page
+func linkedTableFolders(index []byte) ([]string, error) {
+	doc, err := utils.LoadLocalHtml(index)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]string, 0)
+	for _, href := range scraper.RankingIndexLinks(doc) {
+		if folder, ok := scraper.RankingIndexFolder(href); ok {
+			out = append(out, folder)
+		}
+	}
+
+	return out, nil
+}
+
+// checkLinkedPages compares the pages saved in a table folder with the ones linked by its sub-index page.
+// Folders saved without the sub-index (older scrapes, migrated rankings) are not checked.
+func checkLinkedPages(root, rel, id, folder string, count int) []Issue {
+	subIndex, err := os.ReadFile(path.Join(root, scraper.RankingSubIndexFilename(folder)))
+	if err != nil {
+		return nil
+	}
+
+	doc, err := utils.LoadLocalHtml(subIndex)
+	if err != nil {
+		return []Issue{{Kind: IssueMissingIndex, RankingId: id, Path: path.Join(rel, scraper.RankingSubIndexFilename(folder)), Message: fmt.Sprintf("could not parse sub-index: %s", err)}}
+	}
+
+	hrefs := scraper.RankingTableLinks(doc)
+	slices.Sort(hrefs)
+	hrefs = slices.Compact(hrefs)
+
+	missing := make([]string, 0)
+	for _, href := range hrefs {
+		if _, err := os.Stat(path.Join(root, folder, href)); err != nil {
+			missing = append(missing, href)
+		}
+	}
+
+	if len(missing) == 0 && count == len(hrefs) {
+		return nil
+	}
+
+	msg := fmt.Sprintf("the sub-index links %d pages, %d pages were saved", len(hrefs), count)
+	if len(missing) > 0 {
+		msg += fmt.Sprintf(", missing: %s", strings.Join(missing, ", "))
+	}
+	return []Issue{{Kind: IssuePageCountMismatch, RankingId: id, Path: path.Join(rel, folder), Message: msg}}
+}
+
+func countHtmlPages(absPath string) int {
+	entries, err := os.ReadDir(absPath)
+	if err != nil {
+		return 0
+	}
+
+	count := 0
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ".html") {
+			count++
+		}
+	}
+
+	return count
+}
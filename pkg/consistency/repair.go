@@ -0,0 +1,168 @@
+package consistency
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path"
+	"slices"
+
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/config"
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/constants"
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/parser"
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/scraper"
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/utils"
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/writer"
+)
+
+type RepairAction string
+
+const (
+	// delete orphan outputs and html folders without any page
+	RepairDelete RepairAction = "delete"
+	// (re-)download missing, empty or incomplete html folders
+	RepairDownload RepairAction = "download"
+	// parse html folders without output (and the re-downloaded ones)
+	RepairParse RepairAction = "parse"
+)
+
+var RepairActions = []RepairAction{RepairDelete, RepairDownload, RepairParse}
+
+// Repair fixes the issues of the report with the given actions,
+// always in the delete -> download -> parse order.
+// Indexes are not regenerated, run the parser afterwards to update them.
+// Parsed rankings are written following the out config (indentation, birth date policy).
+func (c *Checker) Repair(report Report, actions []RepairAction, out config.OutputConfig) error {
+	errs := make([]error, 0)
+	toParse := []string{}
+	for _, i := range report.ByKind(IssueNotParsed) {
+		toParse = append(toParse, i.RankingId)
+	}
+
+	if slices.Contains(actions, RepairDelete) {
+		errs = append(errs, c.repairDelete(report)...)
+	}
+
+	if slices.Contains(actions, RepairDownload) {
+		downloaded, err := c.repairDownload(report)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		toParse = append(toParse, downloaded...)
+	}
+
+	if slices.Contains(actions, RepairParse) {
+		errs = append(errs, c.repairParse(toParse, out)...)
+	}
+
+	return errors.Join(errs...)
+}
+
+func (c *Checker) repairDelete(report Report) []error {
+	errs := make([]error, 0)
+	for _, i := range report.ByKind(IssueOrphanOutput) {
+		slog.Info("[consistency] REPAIR deleting orphan output", "path", i.Path)
+		if err := os.Remove(path.Join(c.dataDir, i.Path)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	for _, i := range report.ByKind(IssueEmptyFolder) {
+		if i.Path != c.htmlRel(i.RankingId) {
+			continue // only whole ranking folders, not single table folders
+		}
+
+		slog.Info("[consistency] REPAIR deleting empty html folder", "path", i.Path)
+		if err := os.RemoveAll(path.Join(c.dataDir, i.Path)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errs
+}
+
+func (c *Checker) repairDownload(report Report) ([]string, error) {
+	links := []string{}
+	for _, i := range report.Issues {
+		switch i.Kind {
+		case IssueMissingHtml, IssueNotDownloaded:
+			links = append(links, i.Link)
+		case IssueMissingIndex, IssueEmptyFolder, IssuePageCountMismatch:
+			if link, ok := c.scraped[i.RankingId]; ok {
+				links = append(links, link)
+			}
+		}
+	}
+	slices.Sort(links)
+	links = slices.Compact(links)
+
+	if len(links) == 0 {
+		return nil, nil
+	}
+
+	slog.Info("[consistency] REPAIR downloading rankings", "count", len(links))
+	htmlDir := path.Join(c.dataDir, constants.OutputHtmlFolder)
+	downloaded, scraped, broken := []string{}, []string{}, []string{}
+	errs := make([]error, 0)
+	for _, r := range scraper.DownloadRankings(links) {
+		if r.PageCount == 0 {
+			slog.Error("[consistency] REPAIR ranking is empty, probably its link is a 404", "link", r.Url.String())
+			broken = append(broken, r.Url.String())
+			continue
+		}
+
+		// start from a clean folder, so no stale page is left
+		if err := os.RemoveAll(path.Join(htmlDir, r.Id)); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		if err := r.Save(htmlDir); err != nil {
+			errs = append(errs, fmt.Errorf("could not save ranking %s: %w", r.Id, err))
+			continue
+		}
+
+		downloaded = append(downloaded, r.Id)
+		scraped = append(scraped, r.Url.String())
+	}
+
+	// links found by the bruteforce are added to the scraped/broken lists
+	lm := scraper.NewLinksManager(path.Join(c.dataDir, constants.OutputLinksFolder))
+	lm.SetNewLinks(scraped, broken)
+	lm.Write(false)
+
+	return downloaded, errors.Join(errs...)
+}
+
+func (c *Checker) repairParse(ids []string, out config.OutputConfig) []error {
+	errs := make([]error, 0)
+	if len(ids) == 0 {
+		return errs
+	}
+
+	w := writer.NewWriter[parser.Ranking](path.Join(c.dataDir, constants.OutputBaseFolder, constants.OutputParsedRankingsFolder))
+	for _, id := range ids {
+		htmlPath := path.Join(c.dataDir, constants.OutputHtmlFolder, id)
+		if exists, _ := utils.DoFolderExists(htmlPath); !exists {
+			continue // deleted by the delete action
+		}
+
+		ranking := parser.NewRankingParser(htmlPath).Parse()
+		if ranking == nil {
+			errs = append(errs, fmt.Errorf("could not parse ranking %s", id))
+			continue
+		}
+
+		ranking.ApplyBirthDatePolicy(out.BirthDatePolicy)
+		if err := w.JsonWrite(id+".json", *ranking, out.IndentRankings); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		slog.Info("[consistency] REPAIR parsed ranking", "id", id)
+	}
+
+	slog.Warn("[consistency] REPAIR indexes are not regenerated, run the parser to update them", "parsed", len(ids))
+	return errs
+}
@@ -1,15 +1,23 @@
 package constants
 
 const (
-	OutputHtmlFolder            = "html"
-	OutputLinksFolder           = "links"
-	OutputBruteForceFolder      = "bruteforce"
-	OutputScrapedLinksFilename  = "scraped.json"
-	OutputBrokenLinksFilename   = "broken.json"
-	OutputStatsFilname          = "stats.json"
-	OutputManifestiListFilename = "manifesti_list.json"
+	OutputHtmlFolder               = "html"
+	OutputLinksFolder              = "links"
+	OutputBruteForceFolder         = "bruteforce"
+	OutputScrapedLinksFilename     = "scraped.json"
+	OutputBrokenLinksFilename      = "broken.json"
+	OutputManualLinksFilename      = "manual.json"
+	OutputLinkSourcesFilename      = "sources.json"
+	OutputBruteForceModelFilename  = "model.json"
+	OutputStatsFilname             = "stats.json"
+	OutputManifestiListFilename    = "manifesti_list.json"
+	OutputManifestiSnapshotsFolder = "manifesti_snapshots"
+	OutputManifestiHtmlFolder      = "manifesti_html"
+	OutputHttpCacheFolder          = "http_cache"
+	OutputWarcFolder               = "warc"
 
 	OutputHtmlRanking_IndexFilename  = "index.html"
+	OutputHtmlRanking_SubIndexPrefix = "index_" // index_<folder>.html
 	OutputHtmlRanking_ByIdFolder     = "by_id"
 	OutputHtmlRanking_ByMeritFolder  = "by_merit"
 	OutputHtmlRanking_ByCourseFolder = "by_course"
@@ -17,11 +25,25 @@ const (
 	OutputBaseFolder                 = "output"
 	OutputParsedManifestiFolder      = "manifesti"
 	OutputParsedManifestiAllFilename = "all.json"
+	OutputStudyPlansFolder           = "plans" // in OutputParsedManifestiFolder
 	OutputParsedRankingsFolder       = "rankings"
 	OutputIndexesFolder              = "indexes"
 
-	OutputIndexBySchoolYearFilename = "bySchoolYear.json"
-	OutputIndexByYearSchoolFilename = "byYearSchool.json"
+	OutputIndexBySchoolYearFilename          = "bySchoolYear.json"
+	OutputIndexByYearSchoolFilename          = "byYearSchool.json"
+	OutputIndexByStudentIdHashFilename       = "byStudentIdHash.json"
+	OutputIndexDiffsFilename                 = "diffs.json"
+	OutputIndexManifestiAvailabilityFilename = "manifestiAvailability.json"
+
+	OutputDiffsFolder         = "diffs"
+	OutputSeatFlowFolder      = "seatFlow"
+	OutputRankingsStatsFolder = "stats"
+	OutputAdmissionFolder     = "admission"
+
+	OutputSchemasFolder       = "schemas"
+	OutputParseReportFilename = "parseReport.json"
+	// bump it on every change of the output shape, and add an entry in docs/SCHEMA_CHANGELOG.md
+	OutputSchemaVersion = 16
 
 	TmpDirectoryName = "tmp"
 )
@@ -9,9 +9,13 @@ const (
 	WebPolimiIngCivUrl    = "https://www.polimi.it/formazione/corsi-di-laurea/dettaglio-corso/ingegneria-per-lambiente-e-il-territorio"
 	WebPolimiIngInfIndUrl = "https://www.polimi.it/formazione/corsi-di-laurea/dettaglio-corso/ingegneria-informatica"
 	WebPolimiArchUrbUrl   = "https://www.polimi.it/formazione/corsi-di-laurea/dettaglio-corso/ingegneria-edile-architettura"
+	// fallback, when the school course pages above don't work
+	WebPolimiCourseListUrl = "https://www.polimi.it/formazione/corsi-di-laurea"
 
 	WebGithubStableDataUrl    = "https://github.com/PoliNetworkOrg/RankingsDati/tree/stable/data"
 	WebGithubMainDataUrl      = "https://github.com/PoliNetworkOrg/RankingsDati/tree/main/data"
 	WebGithubMainRawDataUrl   = "https://raw.githubusercontent.com/PoliNetworkOrg/RankingsDati/refs/heads/main/data"
 	WebGithubStableRawDataUrl = "https://raw.githubusercontent.com/PoliNetworkOrg/RankingsDati/refs/heads/stable/data"
+
+	HttpDefaultUserAgent = "PoliNetworkRankingsBot/1.0 (+https://github.com/PoliNetworkOrg/rankings-backend-go)"
 )
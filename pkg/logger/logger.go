@@ -6,6 +6,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/config"
 	"github.com/lmittmann/tint"
 	"github.com/mattn/go-isatty"
 )
@@ -22,6 +23,24 @@ func GetDefaultLogger() *slog.Logger {
 		}))
 }
 
+// NewLogger creates the logger from the effective config (see config.LogConfig)
+func NewLogger(cfg config.LogConfig) *slog.Logger {
+	w := os.Stdout
+
+	level, err := parseLogLevel(cfg.Level)
+	if err != nil {
+		slog.Warn("[slog] Invalid log level in config, fallback to info. Supported levels (case-insensitive): debug, info, warn, error", "level", cfg.Level)
+	}
+
+	return slog.New(
+		tint.NewHandler(w, &tint.Options{
+			AddSource:  cfg.AddSource,
+			Level:      level,
+			TimeFormat: time.Kitchen,
+			NoColor:    cfg.NoColor || !isatty.IsTerminal(w.Fd()),
+		}))
+}
+
 func getEnvLogLevel(defaultLevel slog.Level) slog.Level {
 	// Read log level from environment variable
 	level := defaultLevel
@@ -0,0 +1,40 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+)
+
+// CommandSink runs a shell command, passing the JSON notification on stdin
+// and the human readable text in the NOTIFICATION_TEXT env variable
+type CommandSink struct {
+	Command string
+}
+
+func NewCommandSink(command string) *CommandSink {
+	return &CommandSink{Command: command}
+}
+
+func (s *CommandSink) Name() string {
+	return "command"
+}
+
+func (s *CommandSink) Send(ctx context.Context, n Notification) error {
+	body, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", s.Command)
+	cmd.Stdin = bytes.NewReader(body)
+	cmd.Env = append(cmd.Environ(), "NOTIFICATION_TEXT="+n.Text())
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("command failed: %w. output: %s", err, string(out))
+	}
+
+	return nil
+}
@@ -0,0 +1,65 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+)
+
+type EmailSink struct {
+	Host     string // host:port
+	Username string
+	Password string
+	From     string
+	To       []string
+}
+
+func NewEmailSink(host, username, password, from string, to []string) *EmailSink {
+	return &EmailSink{Host: host, Username: username, Password: password, From: from, To: to}
+}
+
+func (s *EmailSink) Name() string {
+	return "email"
+}
+
+func (s *EmailSink) Send(ctx context.Context, n Notification) error {
+	hostname, _, err := net.SplitHostPort(s.Host)
+	if err != nil {
+		return fmt.Errorf("invalid smtp host, expected host:port. error: %w", err)
+	}
+
+	var auth smtp.Auth
+	if s.Username != "" {
+		auth = smtp.PlainAuth("", s.Username, s.Password, hostname)
+	}
+
+	subject := "New rankings published"
+	if len(n.Rankings) == 1 {
+		r := n.Rankings[0]
+		subject = fmt.Sprintf("New ranking published: %s %d - %s", r.School, r.Year, r.Phase)
+	}
+
+	msg := strings.Join([]string{
+		"From: " + s.From,
+		"To: " + strings.Join(s.To, ", "),
+		"Subject: " + subject,
+		"Content-Type: text/plain; charset=UTF-8",
+		"",
+		n.Text(),
+	}, "\r\n")
+
+	// net/smtp does not support contexts, so we run it in a goroutine
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(s.Host, auth, s.From, s.To, []byte(msg))
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
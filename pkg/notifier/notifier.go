@@ -0,0 +1,121 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// NewRanking describes a ranking that has just been downloaded by the scraper
+type NewRanking struct {
+	Id     string `json:"id"`
+	School string `json:"school"`
+	Year   uint16 `json:"year"`
+	Phase  string `json:"phase"`
+	Link   string `json:"link"`
+}
+
+type Notification struct {
+	FoundAt  time.Time    `json:"foundAt"`
+	Rankings []NewRanking `json:"rankings"`
+}
+
+// Sink is a destination of notifications (webhook, telegram, email, ...)
+type Sink interface {
+	Name() string
+	Send(ctx context.Context, n Notification) error
+}
+
+type Notifier struct {
+	sinks      []Sink
+	retries    int
+	retryDelay time.Duration
+	timeout    time.Duration
+	dryRun     bool
+}
+
+func NewNotifier(retries int, retryDelay time.Duration, dryRun bool) *Notifier {
+	return &Notifier{
+		sinks:      []Sink{},
+		retries:    retries,
+		retryDelay: retryDelay,
+		timeout:    30 * time.Second,
+		dryRun:     dryRun,
+	}
+}
+
+func (n *Notifier) AddSink(s Sink) {
+	n.sinks = append(n.sinks, s)
+}
+
+func (n *Notifier) HasSinks() bool {
+	return len(n.sinks) > 0
+}
+
+// Notify sends the notification to every registered sink, retrying each failed sink
+// up to n.retries times. A failing sink does not prevent the others from being notified.
+func (n *Notifier) Notify(rankings []NewRanking) error {
+	if len(rankings) == 0 {
+		slog.Info("[notifier] no new rankings, nothing to notify")
+		return nil
+	}
+
+	notification := Notification{FoundAt: time.Now(), Rankings: rankings}
+	errs := make([]error, 0)
+	for _, sink := range n.sinks {
+		slog := slog.With("sink", sink.Name(), "rankings", len(rankings))
+		if n.dryRun {
+			slog.Info("[notifier] DRY-RUN, not sending notification", "text", notification.Text())
+			continue
+		}
+
+		if err := n.sendWithRetry(sink, notification); err != nil {
+			slog.Error("[notifier] could not send notification", "error", err)
+			errs = append(errs, fmt.Errorf("sink %s: %w", sink.Name(), err))
+			continue
+		}
+
+		slog.Info("[notifier] notification sent")
+	}
+
+	return errors.Join(errs...)
+}
+
+func (n *Notifier) sendWithRetry(sink Sink, notification Notification) error {
+	var err error
+	for attempt := 0; attempt <= n.retries; attempt++ {
+		if attempt > 0 {
+			slog.Warn("[notifier] retrying notification", "sink", sink.Name(), "attempt", attempt, "error", err)
+			time.Sleep(n.retryDelay * time.Duration(attempt))
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), n.timeout)
+		err = sink.Send(ctx, notification)
+		cancel()
+		if err == nil {
+			return nil
+		}
+	}
+
+	return err
+}
+
+// Text is the human readable version of the notification, used by the
+// telegram, email and command sinks
+func (n Notification) Text() string {
+	sb := strings.Builder{}
+	if len(n.Rankings) == 1 {
+		sb.WriteString("New ranking published!\n")
+	} else {
+		sb.WriteString(fmt.Sprintf("%d new rankings published!\n", len(n.Rankings)))
+	}
+
+	for _, r := range n.Rankings {
+		sb.WriteString(fmt.Sprintf("\n%s %d - %s\n%s\n", r.School, r.Year, r.Phase, r.Link))
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,186 @@
+package notifier
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+var testRankings = []NewRanking{{
+	Id:     "2024_20103_abcd",
+	School: "Ingegneria",
+	Year:   2024,
+	Phase:  "Prima fase",
+	Link:   "https://example.com/2024_20103_abcd_html/index.html",
+}}
+
+// recorder is a stand-in server that fails the first `failures` requests with a 503
+type recorder struct {
+	mu       sync.Mutex
+	failures int
+	paths    []string
+	bodies   [][]byte
+}
+
+func (r *recorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.paths = append(r.paths, req.URL.Path)
+	r.bodies = append(r.bodies, body)
+	if req.Header.Get("Content-Type") != "application/json" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if len(r.bodies) <= r.failures {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (r *recorder) requests() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.bodies)
+}
+
+func newTestServer(t *testing.T, failures int) (*httptest.Server, *recorder) {
+	t.Helper()
+	rec := &recorder{failures: failures}
+	srv := httptest.NewServer(rec)
+	t.Cleanup(srv.Close)
+	return srv, rec
+}
+
+func TestWebhookSinkPayload(t *testing.T) {
+	srv, rec := newTestServer(t, 0)
+	n := NewNotifier(0, time.Millisecond, false)
+	n.AddSink(NewWebhookSink(srv.URL + "/hook"))
+
+	if err := n.Notify(testRankings); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	if rec.requests() != 1 || rec.paths[0] != "/hook" {
+		t.Fatalf("got requests %v, want one to /hook", rec.paths)
+	}
+
+	var got Notification
+	if err := json.Unmarshal(rec.bodies[0], &got); err != nil {
+		t.Fatalf("payload is not a notification: %v", err)
+	}
+	if len(got.Rankings) != 1 || got.Rankings[0] != testRankings[0] {
+		t.Errorf("got rankings %+v, want %+v", got.Rankings, testRankings)
+	}
+	if got.FoundAt.IsZero() {
+		t.Error("foundAt is not set")
+	}
+}
+
+func TestTelegramSinkPayload(t *testing.T) {
+	srv, rec := newTestServer(t, 0)
+	sink := NewTelegramSink("123:abc", "-10042")
+	sink.ApiUrl = srv.URL
+	n := NewNotifier(0, time.Millisecond, false)
+	n.AddSink(sink)
+
+	if err := n.Notify(testRankings); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	if rec.requests() != 1 || rec.paths[0] != "/bot123:abc/sendMessage" {
+		t.Fatalf("got requests %v, want one to /bot123:abc/sendMessage", rec.paths)
+	}
+
+	var got struct {
+		ChatId  string `json:"chat_id"`
+		Text    string `json:"text"`
+		Preview bool   `json:"disable_web_page_preview"`
+	}
+	if err := json.Unmarshal(rec.bodies[0], &got); err != nil {
+		t.Fatalf("payload is not a sendMessage request: %v", err)
+	}
+	if got.ChatId != "-10042" || !got.Preview {
+		t.Errorf("got chat_id %q preview %v, want -10042 true", got.ChatId, got.Preview)
+	}
+	for _, want := range []string{"New ranking published!", "Ingegneria 2024 - Prima fase", testRankings[0].Link} {
+		if !strings.Contains(got.Text, want) {
+			t.Errorf("text %q does not contain %q", got.Text, want)
+		}
+	}
+}
+
+func TestNotifyRetry(t *testing.T) {
+	tests := []struct {
+		name         string
+		failures     int
+		retries      int
+		wantRequests int
+		wantErr      bool
+	}{
+		{name: "no failures", failures: 0, retries: 2, wantRequests: 1},
+		{name: "recovers after 5xx", failures: 2, retries: 2, wantRequests: 3},
+		{name: "gives up after retries", failures: 5, retries: 2, wantRequests: 3, wantErr: true},
+		{name: "no retries", failures: 1, retries: 0, wantRequests: 1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			webhookSrv, webhookRec := newTestServer(t, tt.failures)
+			telegramSrv, telegramRec := newTestServer(t, tt.failures)
+			telegram := NewTelegramSink("token", "chat")
+			telegram.ApiUrl = telegramSrv.URL
+
+			n := NewNotifier(tt.retries, time.Millisecond, false)
+			n.AddSink(NewWebhookSink(webhookSrv.URL))
+			n.AddSink(telegram)
+
+			err := n.Notify(testRankings)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Notify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := webhookRec.requests(); got != tt.wantRequests {
+				t.Errorf("webhook got %d requests, want %d", got, tt.wantRequests)
+			}
+			if got := telegramRec.requests(); got != tt.wantRequests {
+				t.Errorf("telegram got %d requests, want %d", got, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestNotifyDryRun(t *testing.T) {
+	webhookSrv, webhookRec := newTestServer(t, 0)
+	telegramSrv, telegramRec := newTestServer(t, 0)
+	telegram := NewTelegramSink("token", "chat")
+	telegram.ApiUrl = telegramSrv.URL
+
+	n := NewNotifier(2, time.Millisecond, true)
+	n.AddSink(NewWebhookSink(webhookSrv.URL))
+	n.AddSink(telegram)
+
+	if err := n.Notify(testRankings); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	if webhookRec.requests() != 0 || telegramRec.requests() != 0 {
+		t.Errorf("dry run sent %d webhook and %d telegram requests, want none", webhookRec.requests(), telegramRec.requests())
+	}
+}
+
+func TestNotifyNoRankings(t *testing.T) {
+	srv, rec := newTestServer(t, 0)
+	n := NewNotifier(0, time.Millisecond, false)
+	n.AddSink(NewWebhookSink(srv.URL))
+
+	if err := n.Notify(nil); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	if rec.requests() != 0 {
+		t.Errorf("got %d requests, want none", rec.requests())
+	}
+}
@@ -0,0 +1,45 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+)
+
+const telegramApiUrl = "https://api.telegram.org"
+
+// TelegramSink sends the notification text to a chat (or channel) through the Telegram bot API
+type TelegramSink struct {
+	// ApiUrl can be overridden to point to a local stand-in
+	ApiUrl string
+	token  string
+	chatId string
+	client *http.Client
+}
+
+func NewTelegramSink(token, chatId string) *TelegramSink {
+	return &TelegramSink{ApiUrl: telegramApiUrl, token: token, chatId: chatId, client: &http.Client{}}
+}
+
+func (s *TelegramSink) Name() string {
+	return "telegram"
+}
+
+func (s *TelegramSink) Send(ctx context.Context, n Notification) error {
+	endpoint, err := url.JoinPath(s.ApiUrl, "bot"+s.token, "sendMessage")
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(map[string]any{
+		"chat_id":                  s.chatId,
+		"text":                     n.Text(),
+		"disable_web_page_preview": true,
+	})
+	if err != nil {
+		return err
+	}
+
+	return postJson(ctx, s.client, endpoint, body)
+}
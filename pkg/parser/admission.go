@@ -0,0 +1,249 @@
+package parser
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/constants"
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/utils"
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/writer"
+)
+
+const (
+	DefaultAdmissionMinYears = 3
+	// with few years the sample stddev can be ~0, which would give a step function
+	minCutoffStdDev = 1
+	// points of the lookup table
+	admissionTableSize = 30
+)
+
+// AdmissionSample is the cutoff of a course in one ranking of the history
+type AdmissionSample struct {
+	Year      uint16 `json:"year"`
+	RankingId string `json:"rankingId"`
+	Cutoff    Cutoff `json:"cutoff"`
+	Assigned  int    `json:"assigned"`
+}
+
+type AdmissionPoint struct {
+	Score       float32 `json:"score"`
+	Probability float32 `json:"probability"`
+}
+
+// AdmissionPhase models the cutoff of a course in the same phase of different years
+// (same Phase.Primary, Phase.Secondary, language and extra-eu flag)
+type AdmissionPhase struct {
+	Primary   uint8  `json:"primary"`
+	Secondary uint8  `json:"secondary"`
+	Language  string `json:"language"`
+	IsExtraEu bool   `json:"isExtraEu"`
+	Label     string `json:"label"` // Phase.Stripped of the latest year
+
+	Samples []AdmissionSample `json:"samples"` // sorted by year
+	// the cutoff result is modeled as a normal distribution
+	Mean   float32 `json:"mean"`
+	StdDev float32 `json:"stdDev"`
+
+	// less than AdmissionModel.MinYears samples: the estimate is not reliable
+	InsufficientHistory bool `json:"insufficientHistory"`
+	// score -> probability that the cutoff is not above it
+	Table []AdmissionPoint `json:"table"`
+}
+
+type AdmissionCourse struct {
+	CourseRef
+	Phases []AdmissionPhase `json:"phases"`
+	// true if every phase has insufficient history
+	InsufficientHistory bool `json:"insufficientHistory"`
+}
+
+// AdmissionModel estimates, for each course of a school, the probability of being
+// admitted with a given result, from the cutoffs of the previous years
+type AdmissionModel struct {
+	SchemaVersion uint              `json:"schemaVersion"`
+	School        string            `json:"school"`
+	MinYears      int               `json:"minYears"`
+	Years         []uint16          `json:"years"` // years with at least one ranking
+	Courses       []AdmissionCourse `json:"courses"`
+}
+
+func (m *AdmissionModel) Filename() string {
+	return utils.MakeFilename(m.School, ".json")
+}
+
+// FindCourses returns the courses whose title contains the given text (case insensitive),
+// filtered by location if not empty
+func (m *AdmissionModel) FindCourses(title, location string) []AdmissionCourse {
+	out := make([]AdmissionCourse, 0)
+	for _, c := range m.Courses {
+		if !strings.Contains(strings.ToLower(c.Title), strings.ToLower(title)) {
+			continue
+		}
+		if location != "" && !strings.EqualFold(c.Location, location) {
+			continue
+		}
+		out = append(out, c)
+	}
+
+	return out
+}
+
+// Probability is the estimated probability that the cutoff is not above the given result
+func (p *AdmissionPhase) Probability(result float32) float32 {
+	z := (float64(result) - float64(p.Mean)) / (float64(p.StdDev) * math.Sqrt2)
+	return float32(math.Round(0.5*(1+math.Erf(z))*1000) / 1000)
+}
+
+// NewAdmissionModels builds one model per school from every ranking of the history
+func NewAdmissionModels(rankings []Ranking, minYears int) []*AdmissionModel {
+	type phaseKey struct {
+		course    CourseRef
+		primary   uint8
+		secondary uint8
+		language  string
+		isExtraEu bool
+	}
+
+	bySchool := map[string]map[phaseKey]*AdmissionPhase{}
+	years := map[string]map[uint16]bool{}
+	for i := range rankings {
+		r := &rankings[i]
+		if _, ok := bySchool[r.School]; !ok {
+			bySchool[r.School] = map[phaseKey]*AdmissionPhase{}
+			years[r.School] = map[uint16]bool{}
+		}
+		years[r.School][r.Year] = true
+
+		for course, sample := range admissionSamples(r) {
+			key := phaseKey{course, r.Phase.Primary, r.Phase.Secondary, r.Phase.Language, r.Phase.IsExtraEu}
+			p, ok := bySchool[r.School][key]
+			if !ok {
+				p = &AdmissionPhase{Primary: key.primary, Secondary: key.secondary, Language: key.language, IsExtraEu: key.isExtraEu}
+				bySchool[r.School][key] = p
+			}
+
+			p.Samples = append(p.Samples, sample)
+			if slices.IndexFunc(p.Samples, func(s AdmissionSample) bool { return s.Year > r.Year }) == -1 {
+				p.Label = r.Phase.Stripped
+			}
+		}
+	}
+
+	models := make([]*AdmissionModel, 0, len(bySchool))
+	for school, phases := range bySchool {
+		m := &AdmissionModel{
+			SchemaVersion: constants.OutputSchemaVersion,
+			School:        school,
+			MinYears:      minYears,
+			Courses:       []AdmissionCourse{},
+		}
+		for year := range years[school] {
+			m.Years = append(m.Years, year)
+		}
+		slices.Sort(m.Years)
+
+		courses := map[CourseRef]*AdmissionCourse{}
+		for key, p := range phases {
+			p.fit(minYears)
+			if _, ok := courses[key.course]; !ok {
+				courses[key.course] = &AdmissionCourse{CourseRef: key.course, Phases: []AdmissionPhase{}, InsufficientHistory: true}
+			}
+
+			c := courses[key.course]
+			c.Phases = append(c.Phases, *p)
+			c.InsufficientHistory = c.InsufficientHistory && p.InsufficientHistory
+		}
+
+		for _, c := range courses {
+			slices.SortFunc(c.Phases, func(a, b AdmissionPhase) int {
+				return CmpPhases(
+					Phase{Primary: a.Primary, Secondary: a.Secondary, Language: a.Language, IsExtraEu: a.IsExtraEu},
+					Phase{Primary: b.Primary, Secondary: b.Secondary, Language: b.Language, IsExtraEu: b.IsExtraEu},
+				)
+			})
+			m.Courses = append(m.Courses, *c)
+		}
+		slices.SortFunc(m.Courses, func(a, b AdmissionCourse) int {
+			return cmp.Or(cmp.Compare(a.Title, b.Title), cmp.Compare(a.Location, b.Location))
+		})
+
+		models = append(models, m)
+	}
+
+	slices.SortFunc(models, func(a, b *AdmissionModel) int { return cmp.Compare(a.School, b.School) })
+	return models
+}
+
+func WriteAdmissionModels(absOutDir string, models []*AdmissionModel, indent bool) error {
+	w := writer.NewWriter[*AdmissionModel](absOutDir)
+	for _, m := range models {
+		if err := w.JsonWrite(m.Filename(), m, indent); err != nil {
+			return fmt.Errorf("error while writing admission model %s: %w", m.Filename(), err)
+		}
+	}
+
+	return nil
+}
+
+// admissionSamples returns the cutoff of every course of the ranking with at least one admitted student
+func admissionSamples(r *Ranking) map[CourseRef]AdmissionSample {
+	samples := map[CourseRef]AdmissionSample{}
+	for _, row := range r.Rows {
+		c, ok := row.EnrolledCourse()
+		// old rankings without result can't be used
+		if !ok || row.Result == 0 {
+			continue
+		}
+
+		ref := CourseRef{Title: c.Title, Location: c.Location}
+		s, ok := samples[ref]
+		if !ok {
+			s = AdmissionSample{Year: r.Year, RankingId: r.Id}
+		}
+		s.Cutoff = *worseCutoff(&s.Cutoff, row)
+		s.Assigned++
+		samples[ref] = s
+	}
+
+	return samples
+}
+
+func (p *AdmissionPhase) fit(minYears int) {
+	slices.SortFunc(p.Samples, func(a, b AdmissionSample) int {
+		return cmp.Or(cmp.Compare(a.Year, b.Year), cmp.Compare(a.RankingId, b.RankingId))
+	})
+
+	years := map[uint16]bool{}
+	mean := 0.0
+	for _, s := range p.Samples {
+		years[s.Year] = true
+		mean += float64(s.Cutoff.Result)
+	}
+	mean /= float64(len(p.Samples))
+
+	variance := 0.0
+	for _, s := range p.Samples {
+		variance += math.Pow(float64(s.Cutoff.Result)-mean, 2)
+	}
+	if len(p.Samples) > 1 {
+		variance /= float64(len(p.Samples) - 1) // sample variance
+	}
+	stdDev := max(math.Sqrt(variance), minCutoffStdDev)
+
+	p.Mean = float32(math.Round(mean*100) / 100)
+	p.StdDev = float32(math.Round(stdDev*100) / 100)
+	p.InsufficientHistory = len(years) < minYears
+
+	// from ~0% to ~100%
+	step := niceWidth(6 * stdDev / admissionTableSize)
+	lo := math.Floor((mean-3*stdDev)/step) * step
+	hi := math.Ceil((mean+3*stdDev)/step) * step
+	p.Table = make([]AdmissionPoint, 0, admissionTableSize+2)
+	for score := lo; score <= hi+step/2; score += step {
+		s := float32(math.Round(score*100) / 100)
+		p.Table = append(p.Table, AdmissionPoint{Score: s, Probability: p.Probability(s)})
+	}
+}
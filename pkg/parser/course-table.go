@@ -13,6 +13,34 @@ import (
 	"github.com/PuerkitoBio/goquery"
 )
 
+// courseTableRow is a row of a course table, before being merged into the merit table rows
+type courseTableRow struct {
+	position   uint16
+	id         string      // hashed matricola, empty if the ranking does not have it
+	birthDate  *utils.Date // nil if the table does not have it
+	canEnroll  bool
+	status     EnrolmentStatus
+	statusText utils.Label
+
+	result    float32
+	hasResult bool
+
+	englishResult    uint8
+	hasEnglishResult bool
+
+	ofa      map[string]bool    // only the columns available in the table
+	sections map[string]float32 // nil if the table does not have sections
+}
+
+type courseTable struct {
+	title      string
+	titleLabel utils.Label
+	location   string
+	rows       []courseTableRow
+
+	sectionLabels map[string]utils.Label // section name (Italian) -> label
+}
+
 func (p *RankingParser) parseAllCourseTables(pages [][]byte) error {
 	// NOTE!!!
 	// Run this function AFTER having parsed the merit table
@@ -24,21 +52,23 @@ func (p *RankingParser) parseAllCourseTables(pages [][]byte) error {
 		return fmt.Errorf("No course table passed in the parseAllCourseTable func")
 	}
 
-	if p.Ranking.Rows[0].Id == "" {
-		// considering this as expected, so no error returned
-		slog.Warn("This ranking does not have Matricola IDs, so the course table is useless (we can't match data with merit table via the matricola id)", "id", p.Ranking.Id)
-		return nil
-	}
-
 	wg := sync.WaitGroup{}
 	errors := make([]string, 0)
+	tables := make([]*courseTable, 0, len(pages))
 	for _, page := range pages {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			err := p.parseCourseTable(page)
+			table, err := p.readCourseTable(page)
+
+			p.mu.Lock()
+			defer p.mu.Unlock()
 			if err != nil {
 				errors = append(errors, err.Error())
+				return
+			}
+			if table != nil {
+				tables = append(tables, table)
 			}
 		}()
 	}
@@ -48,21 +78,133 @@ func (p *RankingParser) parseAllCourseTables(pages [][]byte) error {
 		return fmt.Errorf("Error(s) during ranking table parsing:\n%s", strings.Join(errors, "\n"))
 	}
 
+	for _, table := range tables {
+		p.Ranking.Labels.Courses[table.title] = table.titleLabel
+		maps.Copy(p.Ranking.Labels.Sections, table.sectionLabels)
+	}
+
+	if p.Ranking.Rows[0].Id == "" {
+		// we can't match data with merit table via the matricola id,
+		// so we fallback to positional matching (see positional-match.go)
+		slog.Info("This ranking does not have Matricola IDs, matching course tables by position, birth date and result", "id", p.Ranking.Id)
+		p.Ranking.Matching = MatchingPositional
+		p.matchCourseTablesByPosition(tables)
+		return nil
+	}
+
+	p.Ranking.Matching = MatchingId
+	for _, table := range tables {
+		p.mergeCourseTableById(table)
+	}
+
 	p.Ranking.Rows = slices.Collect(maps.Values(p.Ranking.rowsById))
 	return nil
 }
 
-func (p *RankingParser) parseCourseTable(html []byte) error {
+func (p *RankingParser) mergeCourseTableById(table *courseTable) {
+	slog := slog.With("ranking-id", p.Ranking.Id, "course-title", table.title, "course-location", table.location)
+
+	for _, row := range table.rows {
+		c := CourseStatus{Title: table.title, TitleLabel: table.titleLabel, Location: table.location, Position: row.position, CanEnroll: row.canEnroll, Status: row.status, StatusText: row.statusText}
+
+		if row.id == "" && p.Ranking.Year > 2020 {
+			slog.Warn("Course table row without matricola ID", "position-in-table", row.position)
+		}
+
+		s, found := p.Ranking.rowsById[row.id] // student row parsed from merit table
+		if !found {
+			// we don't create a zero-valued student row, since it would not have position and result
+			p.Ranking.addWarning(RankingWarning{
+				Kind:      WarningOrphanCourseRow,
+				StudentId: row.id,
+				Position:  row.position,
+				Course:    c.Title,
+				Message:   "course table row without a matching merit table row",
+			})
+			continue
+		}
+
+		if c.CanEnroll && s.CanEnroll && s.Status != StatusUnknown {
+			// the merit table status is more detailed (e.g. booked instead of assigned)
+			c.Status = s.Status
+		}
+
+		s.mergeCourseTableRow(row)
+		s.Courses = append(s.Courses, c)
+		p.Ranking.rowsById[row.id] = s // student row parsed from merit table
+	}
+}
+
+// mergeCourseTableRow copies the student data available only in course tables
+func (s *StudentRow) mergeCourseTableRow(row courseTableRow) {
+	if row.birthDate != nil {
+		s.BirthDate = row.birthDate
+	}
+
+	if row.hasEnglishResult {
+		s.EnglishResult = row.englishResult
+	}
+
+	if s.Ofa == nil && len(row.ofa) > 0 {
+		s.Ofa = make(map[string]bool)
+	}
+
+	for key, value := range row.ofa {
+		if _, exists := s.Ofa[key]; !exists {
+			s.Ofa[key] = value
+		}
+	}
+
+	if row.sections != nil && s.SectionsResults == nil {
+		s.SectionsResults = row.sections
+	}
+}
+
+// readCourseTitles returns the course titles (without location) of the course table pages
+func readCourseTitles(pages [][]byte) []string {
+	titles := make([]string, 0, len(pages))
+	for _, html := range pages {
+		page, err := utils.LoadLocalHtml(html)
+		if err != nil {
+			continue // reported by readCourseTable
+		}
+
+		rawTitle, err := utils.GetTextFragments(page.Find(".CenterBar .titolo").First())
+		if err != nil {
+			continue
+		}
+
+		title, _ := getCourseTitleLocation(rawTitle.It)
+		if title != "" && !slices.Contains(titles, title) {
+			titles = append(titles, title)
+		}
+	}
+
+	return titles
+}
+
+// readCourseTable returns nil if the table is empty
+func (p *RankingParser) readCourseTable(html []byte) (*courseTable, error) {
 	page, err := utils.LoadLocalHtml(html)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	rawTitle, err := utils.GetTextFragments(page.Find(".CenterBar .titolo").First())
+	if err != nil {
+		return nil, err
+	}
+
+	title, location := getCourseTitleLocation(rawTitle.It)
+	titleLabel := utils.Label{It: title}
+	if rawTitle.En != "" {
+		titleLabel.En, _ = getCourseTitleLocation(rawTitle.En)
 	}
 
-	title, location := getCourseTitleLocation((page.Find(".CenterBar .titolo").First()).Text())
 	slog := slog.With("ranking-id", p.Ranking.Id, "course-title", title, "course-location", location)
-	c := CourseStatus{Title: title, Location: location}
+	table := &courseTable{title: title, titleLabel: titleLabel, location: location, rows: make([]courseTableRow, 0), sectionLabels: map[string]utils.Label{}}
 
-	idIdx, birthIdx, posIdx, canEnrollIdx, engResultIdx, firstSectionIdx, ofaEngIdx, ofaTestIdx := -1, -1, -1, -1, -1, -1, -1, -1
+	idIdx, birthIdx, posIdx, canEnrollIdx, resultIdx, engResultIdx, firstSectionIdx, ofaEngIdx, ofaTestIdx := -1, -1, -1, -1, -1, -1, -1, -1, -1
 	sections := make([]string, 0)
 
 	tableHeaderFields := page.Find(".TableDati .elenco-campi th")
@@ -70,7 +212,7 @@ func (p *RankingParser) parseCourseTable(html []byte) error {
 	if isEmptyTable(tableRows, slog) {
 		// we don't need to return an error, since this is an expected behaviour
 		// since Polimi likes to publish empty tables
-		return nil
+		return nil, nil
 	}
 
 	p.Ranking.addCourse(title, location)
@@ -78,16 +220,22 @@ func (p *RankingParser) parseCourseTable(html []byte) error {
 	for _, s := range page.Find(".TableDati tr:not(.elenco-campi) th").EachIter() {
 		firstText, err := utils.GetFirstTextFragment(s)
 		if err != nil {
-			return err
+			return nil, err
+		}
+
+		label, err := utils.GetTextFragments(s)
+		if err != nil {
+			return nil, err
 		}
 
 		sections = append(sections, firstText)
+		table.sectionLabels[firstText] = label
 	}
 
 	for i, s := range tableHeaderFields.EachIter() {
 		firstText, err := utils.GetFirstTextFragment(s)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		text := strings.ToLower(firstText)
@@ -119,6 +267,11 @@ func (p *RankingParser) parseCourseTable(html []byte) error {
 			engResultIdx = idx
 			continue
 		}
+		if strings.Contains(text, "voto") || strings.Contains(text, "punteggio") {
+			// not available in every ranking, but useful for positional matching
+			resultIdx = idx
+			continue
+		}
 		if strings.Contains(text, "ofa inglese") {
 			ofaEngIdx = idx
 			continue
@@ -129,56 +282,72 @@ func (p *RankingParser) parseCourseTable(html []byte) error {
 		}
 	}
 
-	for _, row := range tableRows.EachIter() {
-		items := row.Find("td").Map(func(i int, s *goquery.Selection) string { return s.Text() })
+	for _, tr := range tableRows.EachIter() {
+		items := tr.Find("td").Map(func(i int, s *goquery.Selection) string { return s.Text() })
 		if len(items) == 0 {
 			slog.Warn("Course table: <tr> contains 0 <td>, more in-depth investigation recommended")
 			continue
 		}
 
+		row := courseTableRow{}
 		if pos, err := strconv.ParseUint(p.getFieldByIndex(items, posIdx, "0"), 10, 16); err == nil {
-			c.Position = uint16(pos)
+			row.position = uint16(pos)
 		}
 
 		rawId := p.getFieldByIndex(items, idIdx, "")
 		id := strings.TrimSpace(strings.Replace(rawId, "(Contingente Marco Polo)", "", 1))
-		if id == "" && p.Ranking.Year > 2020 {
-			slog.Warn("Course table row without matricola ID", "position-in-table", c.Position)
-		}
 		if len(id) > 0 {
 			id = utils.HashWithSalt(id)
 		}
+		row.id = id
+
+		if birthIdx != -1 {
+			rawBirthDate := p.getFieldByIndex(items, birthIdx, "")
+			if birthDate, err := utils.ParseTableDate(rawBirthDate); err == nil {
+				row.birthDate = &birthDate
+			} else if rawBirthDate != "" {
+				slog.Warn("Course table: could not parse birth date", "position-in-table", row.position, "error", err)
+			}
+		}
 
-		p.mu.Lock()
-		s := p.Ranking.rowsById[id] // student row parsed from merit table
-
-		s.BirthDate = p.getFieldByIndex(items, birthIdx, "")
+		if resultIdx != -1 {
+			resultText := strings.Replace(p.getFieldByIndex(items, resultIdx, ""), ",", ".", 1)
+			if result, err := strconv.ParseFloat(resultText, 32); err == nil {
+				row.result = float32(result)
+				row.hasResult = true
+			}
+		}
 
 		if engResultIdx != -1 {
 			engResultText := p.getFieldByIndex(items, engResultIdx, "-1")
 			if engResult, err := strconv.ParseUint(engResultText, 10, 8); err == nil {
-				s.EnglishResult = uint8(engResult)
+				row.englishResult = uint8(engResult)
+				row.hasEnglishResult = true
 			}
 		}
 
-		if s.Ofa == nil && (ofaEngIdx != -1 || ofaTestIdx != -1) {
-			s.Ofa = make(map[string]bool)
-		}
-
-		if _, exists := s.Ofa["ENG"]; !exists && ofaEngIdx != -1 {
+		row.ofa = make(map[string]bool)
+		if ofaEngIdx != -1 {
 			slog.Info("OFA ENG VALUE", "value", p.getFieldByIndex(items, ofaEngIdx, "No"))
-			s.Ofa["ENG"] = p.getFieldByIndex(items, ofaEngIdx, "No") != "No"
+			row.ofa["ENG"] = p.getFieldByIndex(items, ofaEngIdx, "No") != "No"
 		}
 
-		if _, exists := s.Ofa["TEST"]; !exists && ofaTestIdx != -1 {
-			s.Ofa["TEST"] = p.getFieldByIndex(items, ofaTestIdx, "No") != "No"
+		if ofaTestIdx != -1 {
+			row.ofa["TEST"] = p.getFieldByIndex(items, ofaTestIdx, "No") != "No"
 		}
 
+		row.status = StatusUnknown
 		if canEnrollIdx != -1 {
-			c.CanEnroll = p.getFieldByIndex(items, canEnrollIdx, "No") != "No"
+			row.statusText = utils.SplitLabel(p.getFieldByIndex(items, canEnrollIdx, "No"))
+			row.status = ParseEnrolmentStatus(row.statusText.It, p.courseTitles)
+
+			var known bool
+			if row.canEnroll, known = row.status.CanEnroll(); !known {
+				row.canEnroll = row.statusText.It != "No"
+			}
 		}
 
-		if firstSectionIdx != -1 && s.SectionsResults == nil {
+		if firstSectionIdx != -1 {
 			sectionsResults := map[string]float32{}
 			for i, section := range sections {
 				idx := i + firstSectionIdx
@@ -188,19 +357,13 @@ func (p *RankingParser) parseCourseTable(html []byte) error {
 				}
 			}
 
-			s.SectionsResults = sectionsResults
+			row.sections = sectionsResults
 		}
 
-		s.Courses = append(s.Courses, c)
-		if p.Ranking.Id == "2024_20055_66d0_html" {
-			slog.Debug("appending course", "course", c)
-		}
-
-		p.Ranking.rowsById[id] = s // student row parsed from merit table
-		p.mu.Unlock()
+		table.rows = append(table.rows, row)
 	}
 
-	return nil
+	return table, nil
 }
 
 func isEmptyTable(tableRows *goquery.Selection, logger *slog.Logger) bool {
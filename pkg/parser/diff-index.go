@@ -0,0 +1,137 @@
+package parser
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/constants"
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/utils"
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/writer"
+)
+
+type diffIndexEntry struct {
+	From     RankingRef `json:"from"`
+	To       RankingRef `json:"to"`
+	Filename string     `json:"filename"` // in the diffs folder
+}
+
+type byDiffSchoolYear = map[string]map[uint][]diffIndexEntry
+
+// phaseStream is the list of rankings of the same school, year, language and extra-eu flag,
+// sorted by phase: each one follows the previous (e.g. "prima graduatoria" -> "seconda graduatoria")
+type phaseStream struct {
+	school    string
+	year      uint16
+	language  string
+	isExtraEu bool
+	rankings  []*Ranking
+}
+
+func phaseStreams(rankings []Ranking) []phaseStream {
+	type streamKey struct {
+		school    string
+		year      uint16
+		language  string
+		isExtraEu bool
+	}
+
+	byKey := map[streamKey][]*Ranking{}
+	for i := range rankings {
+		r := &rankings[i]
+		key := streamKey{r.School, r.Year, r.Phase.Language, r.Phase.IsExtraEu}
+		byKey[key] = append(byKey[key], r)
+	}
+
+	streams := make([]phaseStream, 0, len(byKey))
+	for key, list := range byKey {
+		slices.SortStableFunc(list, func(a, b *Ranking) int { return CmpPhases(a.Phase, b.Phase) })
+		streams = append(streams, phaseStream{key.school, key.year, key.language, key.isExtraEu, list})
+	}
+
+	slices.SortFunc(streams, func(a, b phaseStream) int {
+		return cmp.Or(
+			cmp.Compare(a.school, b.school),
+			cmp.Compare(a.year, b.year),
+			cmp.Compare(langPriority(a.language), langPriority(b.language)),
+			cmp.Compare(boolToInt(a.isExtraEu), boolToInt(b.isExtraEu)),
+		)
+	})
+
+	return streams
+}
+
+// DiffConsecutivePhases diffs every ranking with the next one of its phase stream
+func DiffConsecutivePhases(rankings []Ranking) []*RankingDiff {
+	diffs := make([]*RankingDiff, 0)
+	for _, s := range phaseStreams(rankings) {
+		stream := s.rankings
+		for i := 1; i < len(stream); i++ {
+			diff, err := DiffRankings(stream[i-1], stream[i])
+			if err != nil {
+				continue // can't happen, same school and year
+			}
+			diffs = append(diffs, diff)
+		}
+	}
+
+	return diffs
+}
+
+// WriteDiffs writes the diffs in absOutDir/diffs, then rebuilds the diffs index
+// from every file of the folder (also the ones written by previous runs)
+func WriteDiffs(absOutDir string, diffs []*RankingDiff, indent bool) error {
+	diffsDir := path.Join(absOutDir, constants.OutputDiffsFolder)
+	w := writer.NewWriter[*RankingDiff](diffsDir)
+	for _, d := range diffs {
+		if err := w.JsonWrite(d.DiffFilename(), d, indent); err != nil {
+			return fmt.Errorf("error while writing diff %s: %w", d.DiffFilename(), err)
+		}
+	}
+
+	entries, err := utils.GetEntriesInFolder(diffsDir)
+	if err != nil {
+		return err
+	}
+
+	index := byDiffSchoolYear{}
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+
+		data, err := os.ReadFile(path.Join(diffsDir, e.Name()))
+		if err != nil {
+			return err
+		}
+
+		var d RankingDiff
+		if err := json.Unmarshal(data, &d); err != nil {
+			return fmt.Errorf("could not decode diff %s: %w", e.Name(), err)
+		}
+
+		if _, ok := index[d.School]; !ok {
+			index[d.School] = map[uint][]diffIndexEntry{}
+		}
+		index[d.School][uint(d.Year)] = append(index[d.School][uint(d.Year)], diffIndexEntry{From: d.From, To: d.To, Filename: e.Name()})
+	}
+
+	for _, years := range index {
+		for _, list := range years {
+			slices.SortStableFunc(list, func(a, b diffIndexEntry) int {
+				return cmp.Or(CmpPhases(a.From.Phase, b.From.Phase), CmpPhases(a.To.Phase, b.To.Phase))
+			})
+		}
+	}
+
+	iw := writer.NewWriter[indexFile[byDiffSchoolYear]](path.Join(absOutDir, constants.OutputIndexesFolder))
+	if err := iw.JsonWrite(constants.OutputIndexDiffsFilename, newIndexFile(index), indent); err != nil {
+		return fmt.Errorf("error while writing diffs index: %w", err)
+	}
+
+	return nil
+}
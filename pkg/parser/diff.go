@@ -0,0 +1,265 @@
+package parser
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/constants"
+)
+
+type StudentChange string
+
+const (
+	ChangeAdded         StudentChange = "added"          // only in the later ranking
+	ChangeRemoved       StudentChange = "removed"        // only in the earlier ranking
+	ChangePositionMoved StudentChange = "position_moved" // different merit position
+	ChangeNewlyEnrolled StudentChange = "newly_enrolled" // allowed to enroll only in the later ranking
+	ChangeLostSeat      StudentChange = "lost_seat"      // allowed to enroll only in the earlier ranking
+	ChangeCourseChanged StudentChange = "course_changed" // allowed to enroll in both, but in a different course
+)
+
+type CourseRef struct {
+	Title    string `json:"title"`
+	Location string `json:"location"`
+}
+
+type RankingRef struct {
+	Id    string `json:"id"`
+	Phase Phase  `json:"phase"`
+}
+
+type StudentDelta struct {
+	FromPosition uint16 `json:"fromPosition,omitempty"` // 0 if not in From
+	ToPosition   uint16 `json:"toPosition,omitempty"`   // 0 if not in To
+	// FromPosition - ToPosition, positive when the student moved up
+	PositionChange int `json:"positionChange"`
+
+	FromCourse *CourseRef `json:"fromCourse,omitempty"` // course the student can enroll in
+	ToCourse   *CourseRef `json:"toCourse,omitempty"`
+
+	Changes []StudentChange `json:"changes"`
+}
+
+// Cutoff is the last student allowed to enroll in a course
+type Cutoff struct {
+	Position uint16  `json:"position"`
+	Result   float32 `json:"result"`
+}
+
+type CourseDelta struct {
+	CourseRef
+
+	FromCutoff *Cutoff `json:"fromCutoff,omitempty"` // nil if nobody can enroll
+	ToCutoff   *Cutoff `json:"toCutoff,omitempty"`
+
+	FromAssigned int `json:"fromAssigned"`
+	ToAssigned   int `json:"toAssigned"`
+	// students allowed to enroll in From, but not in this course in To
+	SeatsFreed int `json:"seatsFreed"`
+	// students allowed to enroll in To, but not in this course in From
+	NewlyAssigned int `json:"newlyAssigned"`
+}
+
+// RankingDiff is the delta between two rankings of the same school and year.
+// From is always the earlier phase (see CmpPhases).
+type RankingDiff struct {
+	SchemaVersion uint `json:"schemaVersion"`
+
+	School string     `json:"school"`
+	Year   uint16     `json:"year"`
+	From   RankingRef `json:"from"`
+	To     RankingRef `json:"to"`
+
+	// keyed by student id hash, only students with changes are listed
+	Students map[string]StudentDelta `json:"students"`
+	Courses  []CourseDelta           `json:"courses"`
+
+	// rows without student id (rankings before 2021) can't be compared
+	SkippedRows int `json:"skippedRows"`
+}
+
+// DiffFilename is the name of the diff file in the output diffs folder
+func (d *RankingDiff) DiffFilename() string {
+	return d.From.Id + "__" + d.To.Id + ".json"
+}
+
+// EnrolledCourse returns the course the student is allowed to enroll in, if any
+func (s *StudentRow) EnrolledCourse() (CourseStatus, bool) {
+	idx := slices.IndexFunc(s.Courses, func(c CourseStatus) bool { return c.CanEnroll })
+	if !s.CanEnroll || idx == -1 {
+		return CourseStatus{}, false
+	}
+
+	return s.Courses[idx], true
+}
+
+// DiffRankings compares two rankings of the same school and year, in any order
+func DiffRankings(a, b *Ranking) (*RankingDiff, error) {
+	if a.School != b.School || a.Year != b.Year {
+		return nil, fmt.Errorf("can't diff rankings of different school/year: %s %d (%s), %s %d (%s)", a.School, a.Year, a.Id, b.School, b.Year, b.Id)
+	}
+
+	from, to := a, b
+	if CmpPhases(a.Phase, b.Phase) > 0 {
+		from, to = b, a
+	}
+
+	diff := &RankingDiff{
+		SchemaVersion: constants.OutputSchemaVersion,
+		School:        from.School,
+		Year:          from.Year,
+		From:          RankingRef{Id: from.Id, Phase: from.Phase},
+		To:            RankingRef{Id: to.Id, Phase: to.Phase},
+		Students:      map[string]StudentDelta{},
+		Courses:       []CourseDelta{},
+	}
+
+	fromRows, skippedFrom := rowsByStudentId(from)
+	toRows, skippedTo := rowsByStudentId(to)
+	diff.SkippedRows = skippedFrom + skippedTo
+
+	for _, id := range slices.Sorted(maps.Keys(mapUnion(fromRows, toRows))) {
+		fromRow, inFrom := fromRows[id]
+		toRow, inTo := toRows[id]
+		if delta := diffStudent(fromRow, inFrom, toRow, inTo); len(delta.Changes) > 0 {
+			diff.Students[id] = delta
+		}
+	}
+
+	diff.Courses = diffCourses(from, to, fromRows, toRows)
+	return diff, nil
+}
+
+func rowsByStudentId(r *Ranking) (map[string]StudentRow, int) {
+	rows, skipped := map[string]StudentRow{}, 0
+	for _, row := range r.Rows {
+		if row.Id == "" {
+			skipped++
+			continue
+		}
+		rows[row.Id] = row
+	}
+
+	return rows, skipped
+}
+
+func mapUnion[V any](a, b map[string]V) map[string]struct{} {
+	out := map[string]struct{}{}
+	for k := range a {
+		out[k] = struct{}{}
+	}
+	for k := range b {
+		out[k] = struct{}{}
+	}
+	return out
+}
+
+func diffStudent(from StudentRow, inFrom bool, to StudentRow, inTo bool) StudentDelta {
+	delta := StudentDelta{Changes: []StudentChange{}}
+	if inFrom {
+		delta.FromPosition = from.Position
+		if c, ok := from.EnrolledCourse(); ok {
+			delta.FromCourse = &CourseRef{Title: c.Title, Location: c.Location}
+		}
+	}
+	if inTo {
+		delta.ToPosition = to.Position
+		if c, ok := to.EnrolledCourse(); ok {
+			delta.ToCourse = &CourseRef{Title: c.Title, Location: c.Location}
+		}
+	}
+
+	switch {
+	case !inFrom:
+		delta.Changes = append(delta.Changes, ChangeAdded)
+	case !inTo:
+		delta.Changes = append(delta.Changes, ChangeRemoved)
+	case from.Position != to.Position:
+		delta.PositionChange = int(from.Position) - int(to.Position)
+		delta.Changes = append(delta.Changes, ChangePositionMoved)
+	}
+
+	switch {
+	case delta.FromCourse == nil && delta.ToCourse != nil:
+		delta.Changes = append(delta.Changes, ChangeNewlyEnrolled)
+	case delta.FromCourse != nil && delta.ToCourse == nil && inTo:
+		delta.Changes = append(delta.Changes, ChangeLostSeat)
+	case delta.FromCourse != nil && delta.ToCourse != nil && *delta.FromCourse != *delta.ToCourse:
+		delta.Changes = append(delta.Changes, ChangeCourseChanged)
+	}
+
+	return delta
+}
+
+func diffCourses(from, to *Ranking, fromRows, toRows map[string]StudentRow) []CourseDelta {
+	courses := map[CourseRef]*CourseDelta{}
+	get := func(ref CourseRef) *CourseDelta {
+		if _, ok := courses[ref]; !ok {
+			courses[ref] = &CourseDelta{CourseRef: ref}
+		}
+		return courses[ref]
+	}
+
+	for _, r := range []*Ranking{from, to} {
+		for title, locations := range r.Courses {
+			if len(locations) == 0 {
+				get(CourseRef{Title: title})
+			}
+			for _, l := range locations {
+				get(CourseRef{Title: title, Location: l})
+			}
+		}
+	}
+
+	// cutoffs are computed on every row, also the ones without student id
+	for _, row := range from.Rows {
+		if c, ok := row.EnrolledCourse(); ok {
+			cd := get(CourseRef{Title: c.Title, Location: c.Location})
+			cd.FromAssigned++
+			cd.FromCutoff = worseCutoff(cd.FromCutoff, row)
+		}
+	}
+	for _, row := range to.Rows {
+		if c, ok := row.EnrolledCourse(); ok {
+			cd := get(CourseRef{Title: c.Title, Location: c.Location})
+			cd.ToAssigned++
+			cd.ToCutoff = worseCutoff(cd.ToCutoff, row)
+		}
+	}
+
+	for id, fromRow := range fromRows {
+		fromCourse, fromOk := fromRow.EnrolledCourse()
+		toRow := toRows[id]
+		toCourse, toOk := toRow.EnrolledCourse()
+		if fromOk && (!toOk || fromCourse.Title != toCourse.Title || fromCourse.Location != toCourse.Location) {
+			get(CourseRef{Title: fromCourse.Title, Location: fromCourse.Location}).SeatsFreed++
+		}
+	}
+	for id, toRow := range toRows {
+		toCourse, toOk := toRow.EnrolledCourse()
+		fromRow := fromRows[id]
+		fromCourse, fromOk := fromRow.EnrolledCourse()
+		if toOk && (!fromOk || fromCourse.Title != toCourse.Title || fromCourse.Location != toCourse.Location) {
+			get(CourseRef{Title: toCourse.Title, Location: toCourse.Location}).NewlyAssigned++
+		}
+	}
+
+	out := make([]CourseDelta, 0, len(courses))
+	for _, cd := range courses {
+		out = append(out, *cd)
+	}
+	slices.SortFunc(out, func(a, b CourseDelta) int {
+		return cmp.Or(cmp.Compare(a.Title, b.Title), cmp.Compare(a.Location, b.Location))
+	})
+
+	return out
+}
+
+func worseCutoff(c *Cutoff, row StudentRow) *Cutoff {
+	if c == nil || row.Position > c.Position {
+		return &Cutoff{Position: row.Position, Result: row.Result}
+	}
+	return c
+}
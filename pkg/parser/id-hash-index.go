@@ -6,19 +6,24 @@ import (
 	"slices"
 	"sync"
 
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/constants"
 	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/writer"
 )
 
+type byStudentIdHash = map[string][]string // student id hash -> ranking ids
+
 type IdHashIndexParser struct {
 	outDir string
-	index  map[string][]string
+	indent bool
+	index  byStudentIdHash
 	mu     sync.Mutex
 }
 
-func NewIdHashIndexParser(absOutDir string) *IdHashIndexParser {
+func NewIdHashIndexParser(absOutDir string, indent bool) *IdHashIndexParser {
 	return &IdHashIndexParser{
 		outDir: absOutDir,
-		index:  map[string][]string{},
+		indent: indent,
+		index:  byStudentIdHash{},
 		mu:     sync.Mutex{},
 	}
 }
@@ -39,8 +44,8 @@ func (p *IdHashIndexParser) Add(ranking *Ranking) {
 }
 
 func (p *IdHashIndexParser) Write() error {
-	w := writer.NewWriter[map[string][]string](p.outDir)
-	err := w.JsonWrite("byStudentIdHash.json", p.index, true)
+	w := writer.NewWriter[indexFile[byStudentIdHash]](p.outDir)
+	err := w.JsonWrite(constants.OutputIndexByStudentIdHashFilename, newIndexFile(p.index), p.indent)
 	if err != nil {
 		return fmt.Errorf("error while performing write (1) in IdHashIndexParser, error: %w", err)
 	}
@@ -1,19 +1,42 @@
 package parser
 
 import (
+	"encoding/json"
 	"fmt"
 	"slices"
+	"strconv"
 	"sync"
 
 	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/constants"
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/utils"
 	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/writer"
 )
 
 type indexEntry struct {
-	ID     string `json:"id"`
-	School string `json:"school"`
-	Year   uint   `json:"year"`
-	Phase  Phase  `json:"phase"`
+	ID     string    `json:"id"`
+	School string    `json:"school"`
+	Year   uint      `json:"year"`
+	Phase  Phase     `json:"phase"`
+	Meta   indexMeta `json:"meta"`
+}
+
+// indexMeta is the part of RankingMeta useful to list the rankings
+type indexMeta struct {
+	PublishedAt *utils.Date          `json:"publishedAt,omitempty"`
+	Deadlines   []Deadline           `json:"deadlines"`
+	Pages       map[MetaLinkKind]int `json:"pages"` // saved pages by sub-index kind
+}
+
+func newIndexMeta(meta RankingMeta) indexMeta {
+	pages := map[MetaLinkKind]int{}
+	for _, l := range meta.Links {
+		// links of the same kind (e.g. the index linking by_merit twice) count the pages of the same folder
+		if l.Pages > 0 {
+			pages[l.Kind] = l.Pages
+		}
+	}
+
+	return indexMeta{PublishedAt: meta.PublishedAt, Deadlines: meta.Deadlines, Pages: pages}
 }
 
 type (
@@ -22,23 +45,56 @@ type (
 	// bySchoolCourse = map[uint]map[string][]indexEntry // TODO
 )
 
+// indexFile is the shape of every file written in the indexes folder: the keys of Data
+// (a map or a struct) with schemaVersion next to them, see MarshalJSON
+type indexFile[T any] struct {
+	SchemaVersion uint
+	Data          T
+}
+
+// MarshalJSON writes schemaVersion next to the keys of Data, so the index files keep the shape
+// the frontend reads (from version 1 to 15 the content was wrapped in a "data" field)
+func (f indexFile[T]) MarshalJSON() ([]byte, error) {
+	data, err := json.Marshal(f.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("index data is not a JSON object: %w", err)
+	}
+	if fields == nil {
+		fields = map[string]json.RawMessage{} // nil maps are marshaled as null
+	}
+
+	fields["schemaVersion"] = json.RawMessage(strconv.FormatUint(uint64(f.SchemaVersion), 10))
+	return json.Marshal(fields)
+}
+
+func newIndexFile[T any](data T) indexFile[T] {
+	return indexFile[T]{SchemaVersion: constants.OutputSchemaVersion, Data: data}
+}
+
 type IndexGenerator struct {
 	outDir       string
+	indent       bool
 	entries      []indexEntry
 	byYearSchool byYearSchool
 	bySchoolYear bySchoolYear
 }
 
-func NewIndexGenerator(absOutDir string) *IndexGenerator {
+func NewIndexGenerator(absOutDir string, indent bool) *IndexGenerator {
 	return &IndexGenerator{
 		outDir:       absOutDir,
+		indent:       indent,
 		bySchoolYear: make(bySchoolYear),
 		byYearSchool: make(byYearSchool),
 	}
 }
 
 func (gen *IndexGenerator) Add(ranking *Ranking) {
-	gen.entries = append(gen.entries, indexEntry{ID: ranking.Id, School: ranking.School, Year: uint(ranking.Year), Phase: ranking.Phase})
+	gen.entries = append(gen.entries, indexEntry{ID: ranking.Id, School: ranking.School, Year: uint(ranking.Year), Phase: ranking.Phase, Meta: newIndexMeta(ranking.Meta)})
 }
 
 func (gen *IndexGenerator) makeSchoolYear() {
@@ -96,13 +152,13 @@ func (gen *IndexGenerator) Generate() error {
 }
 
 func (gen *IndexGenerator) write() error {
-	w1 := writer.NewWriter[bySchoolYear](gen.outDir)
-	if err := w1.JsonWrite(constants.OutputIndexBySchoolYearFilename, gen.bySchoolYear, true); err != nil {
+	w1 := writer.NewWriter[indexFile[bySchoolYear]](gen.outDir)
+	if err := w1.JsonWrite(constants.OutputIndexBySchoolYearFilename, newIndexFile(gen.bySchoolYear), gen.indent); err != nil {
 		return fmt.Errorf("error while performing write (1) in IndexGenerator, error: %w", err)
 	}
 
-	w2 := writer.NewWriter[byYearSchool](gen.outDir)
-	if err := w2.JsonWrite(constants.OutputIndexByYearSchoolFilename, gen.byYearSchool, true); err != nil {
+	w2 := writer.NewWriter[indexFile[byYearSchool]](gen.outDir)
+	if err := w2.JsonWrite(constants.OutputIndexByYearSchoolFilename, newIndexFile(gen.byYearSchool), gen.indent); err != nil {
 		return fmt.Errorf("error while performing write (1) in IndexGenerator, error: %w", err)
 	}
 
@@ -0,0 +1,75 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/constants"
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/schema"
+)
+
+func outputSchema(t *testing.T, name string) *schema.Schema {
+	t.Helper()
+	for _, e := range OutputSchemas() {
+		if e.Name == name {
+			return e.Schema
+		}
+	}
+	t.Fatalf("schema %s not found", name)
+	return nil
+}
+
+func TestIndexFileShape(t *testing.T) {
+	index := byYearSchool{2024: {"Ingegneria": []indexEntry{{ID: "2024_20103_abcd_html", School: "Ingegneria", Year: 2024}}}}
+	data, err := json.Marshal(newIndexFile(index))
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	var got map[string]json.RawMessage
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || string(got["schemaVersion"]) != fmt.Sprint(constants.OutputSchemaVersion) || got["2024"] == nil {
+		t.Errorf("got keys of %s, want 2024 and schemaVersion", data)
+	}
+
+	errs, err := outputSchema(t, "index-by-year-school").ValidateJson(data)
+	if err != nil || len(errs) > 0 {
+		t.Errorf("index does not match its schema: %v %v", errs, err)
+	}
+}
+
+func TestIndexFileSchema(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		data   string
+		valid  bool
+	}{
+		{name: "empty map", schema: "index-by-student-id-hash", data: `{"schemaVersion":%d}`, valid: true},
+		{name: "missing schemaVersion", schema: "index-by-student-id-hash", data: `{"abc":["x"]}`},
+		{name: "wrapped in data", schema: "index-by-student-id-hash", data: `{"schemaVersion":%d,"data":{"abc":["x"]}}`},
+		{name: "year keys", schema: "index-by-year-school", data: `{"schemaVersion":%d,"2024":{}}`, valid: true},
+		{name: "not a year key", schema: "index-by-year-school", data: `{"schemaVersion":%d,"Ingegneria":{}}`},
+		{name: "struct", schema: "index-manifesti-availability", data: `{"schemaVersion":%d,"years":[],"courses":[]}`, valid: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := tt.data
+			if strings.Contains(data, "%d") {
+				data = fmt.Sprintf(data, constants.OutputSchemaVersion)
+			}
+			errs, err := outputSchema(t, tt.schema).ValidateJson([]byte(data))
+			if err != nil {
+				t.Fatalf("ValidateJson() error = %v", err)
+			}
+			if (len(errs) == 0) != tt.valid {
+				t.Errorf("ValidateJson(%s) = %v, want valid %v", data, errs, tt.valid)
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package parser
+
+import (
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/utils"
+)
+
+// RankingLabels contains the Italian and English version of the ranking texts,
+// so the English version of the site does not need to show Italian strings.
+// Maps are keyed by the Italian text used in the rest of the ranking.
+type RankingLabels struct {
+	Phase    utils.Label            `json:"phase"`
+	Courses  map[string]utils.Label `json:"courses"`  // Ranking.Courses keys (course titles)
+	Sections map[string]utils.Label `json:"sections"` // StudentRow.SectionsResults keys
+}
+
+func newRankingLabels() RankingLabels {
+	return RankingLabels{
+		Courses:  map[string]utils.Label{},
+		Sections: map[string]utils.Label{},
+	}
+}
+
+// fillLabels completes the course labels of rows built from the merit table,
+// whose status column contains the Italian title only
+func (r *Ranking) fillLabels() {
+	for i := range r.Rows {
+		for j, c := range r.Rows[i].Courses {
+			if label, ok := r.Labels.Courses[c.Title]; ok {
+				r.Rows[i].Courses[j].TitleLabel = label
+			}
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/constants"
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/utils"
+)
+
+// LoadRankings reads every parsed ranking of the output folder (absOutDir/rankings).
+// We don't use writer.Writer here, since it creates missing folders
+// while readers must not touch the output tree.
+func LoadRankings(absOutDir string) ([]Ranking, error) {
+	rankingsDir := path.Join(absOutDir, constants.OutputParsedRankingsFolder)
+	entries, err := utils.GetEntriesInFolder(rankingsDir)
+	if err != nil {
+		return nil, fmt.Errorf("could not read output rankings folder: %w", err)
+	}
+
+	rankings := make([]Ranking, 0, len(entries))
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+
+		r, err := LoadRanking(path.Join(rankingsDir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		rankings = append(rankings, r)
+	}
+
+	return rankings, nil
+}
+
+func LoadRanking(absPath string) (Ranking, error) {
+	var r Ranking
+	data, err := os.ReadFile(absPath)
+	if err != nil {
+		return r, err
+	}
+
+	if err := json.Unmarshal(data, &r); err != nil {
+		return r, fmt.Errorf("could not decode %s: %w", path.Base(absPath), err)
+	}
+
+	return r, nil
+}
@@ -0,0 +1,146 @@
+package parser
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/constants"
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/scraper"
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/writer"
+)
+
+type CourseEventKind string
+
+const (
+	CourseIntroduced      CourseEventKind = "introduced" // not in the previous year (not emitted for the first year)
+	CourseRenamed         CourseEventKind = "renamed"
+	CourseDiscontinued    CourseEventKind = "discontinued" // in the previous year, not in this one
+	CourseLocationAdded   CourseEventKind = "location_added"
+	CourseLocationRemoved CourseEventKind = "location_removed"
+)
+
+type CourseEvent struct {
+	Year uint16          `json:"year"`
+	Kind CourseEventKind `json:"kind"`
+	From string          `json:"from,omitempty"` // previous name (renamed) or removed location
+	To   string          `json:"to,omitempty"`   // new name (renamed) or added location
+}
+
+// CourseAvailability is the history of a course (identified by its k_corso_la code) in the manifesti snapshots
+type CourseAvailability struct {
+	Code       uint64 `json:"code"`
+	Name       string `json:"name"` // latest name
+	School     string `json:"school"`
+	DegreeType string `json:"degreeType"`
+
+	Years     []uint16            `json:"years"`     // academic years with the course
+	Locations map[string][]uint16 `json:"locations"` // location -> academic years
+	Events    []CourseEvent       `json:"events"`    // sorted by year
+}
+
+type manifestiAvailability struct {
+	Years   []uint16             `json:"years"` // academic years with a snapshot
+	Courses []CourseAvailability `json:"courses"`
+}
+
+// courseYear is a course in the latest snapshot of an academic year
+type courseYear struct {
+	name       string
+	school     string
+	degreeType string
+	locations  map[string]bool
+}
+
+func newManifestiAvailability(snapshots []scraper.ManifestiSnapshot) manifestiAvailability {
+	// snapshots are sorted by year and date, so the latest one of each year wins
+	byYear := map[uint16]map[uint64]*courseYear{}
+	for _, s := range snapshots {
+		courses := map[uint64]*courseYear{}
+		for _, m := range s.Manifesti {
+			code := m.CourseCode()
+			if _, ok := courses[code]; !ok {
+				courses[code] = &courseYear{name: m.Name, school: m.School, degreeType: m.DegreeType, locations: map[string]bool{}}
+			}
+			courses[code].locations[m.Location] = true
+		}
+		byYear[s.Year] = courses
+	}
+
+	years := slices.Sorted(maps.Keys(byYear))
+	codes := map[uint64]bool{}
+	for _, courses := range byYear {
+		for code := range courses {
+			codes[code] = true
+		}
+	}
+
+	out := manifestiAvailability{Years: years, Courses: make([]CourseAvailability, 0, len(codes))}
+	if out.Years == nil {
+		out.Years = []uint16{}
+	}
+
+	for code := range codes {
+		c := CourseAvailability{Code: code, Years: []uint16{}, Locations: map[string][]uint16{}, Events: []CourseEvent{}}
+		var prev *courseYear
+		for i, year := range years {
+			cur, ok := byYear[year][code]
+			switch {
+			case !ok && prev != nil:
+				c.Events = append(c.Events, CourseEvent{Year: year, Kind: CourseDiscontinued})
+			case ok && prev == nil && i > 0:
+				c.Events = append(c.Events, CourseEvent{Year: year, Kind: CourseIntroduced})
+			case ok && prev != nil:
+				c.Events = append(c.Events, courseYearEvents(year, prev, cur)...)
+			}
+
+			if ok {
+				c.Name, c.School, c.DegreeType = cur.name, cur.school, cur.degreeType
+				c.Years = append(c.Years, year)
+				for location := range cur.locations {
+					c.Locations[location] = append(c.Locations[location], year)
+				}
+			}
+			prev = cur
+		}
+
+		out.Courses = append(out.Courses, c)
+	}
+
+	slices.SortFunc(out.Courses, func(a, b CourseAvailability) int {
+		return cmp.Or(cmp.Compare(a.School, b.School), cmp.Compare(a.Name, b.Name), cmp.Compare(a.Code, b.Code))
+	})
+	return out
+}
+
+func courseYearEvents(year uint16, prev, cur *courseYear) []CourseEvent {
+	events := []CourseEvent{}
+	if prev.name != cur.name {
+		events = append(events, CourseEvent{Year: year, Kind: CourseRenamed, From: prev.name, To: cur.name})
+	}
+
+	for _, location := range slices.Sorted(maps.Keys(cur.locations)) {
+		if !prev.locations[location] {
+			events = append(events, CourseEvent{Year: year, Kind: CourseLocationAdded, To: location})
+		}
+	}
+	for _, location := range slices.Sorted(maps.Keys(prev.locations)) {
+		if !cur.locations[location] {
+			events = append(events, CourseEvent{Year: year, Kind: CourseLocationRemoved, From: location})
+		}
+	}
+
+	return events
+}
+
+// WriteManifestiAvailability writes the course availability index, built from the manifesti snapshots
+func WriteManifestiAvailability(absIndexesDir string, snapshots []scraper.ManifestiSnapshot, indent bool) error {
+	w := writer.NewWriter[indexFile[manifestiAvailability]](absIndexesDir)
+	err := w.JsonWrite(constants.OutputIndexManifestiAvailabilityFilename, newIndexFile(newManifestiAvailability(snapshots)), indent)
+	if err != nil {
+		return fmt.Errorf("error while writing manifesti availability index: %w", err)
+	}
+
+	return nil
+}
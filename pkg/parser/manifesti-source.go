@@ -0,0 +1,84 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/constants"
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/scraper"
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/utils"
+)
+
+const (
+	ManifestiSourceRemote    = "remote" // old-format manifesti.json of the data repository, on GitHub
+	ManifestiSourceLocal     = "local"  // manifesti_list.json of the data folder
+	ManifestiSourceGitPrefix = "git:"   // manifesti_list.json of the data folder at a git revision, e.g. git:HEAD~1
+)
+
+// LoadManifestiSource reads a manifesti list from a source: "remote", "local", "git:<revision>",
+// or the path of a JSON file (manifesti_list.json or old-format manifesti.json)
+func LoadManifestiSource(source, absDataDir, rawDataUrl string) ([]scraper.Manifesto, error) {
+	var data []byte
+	var err error
+	switch {
+	case source == ManifestiSourceRemote:
+		data, err = fetchRemoteManifesti(rawDataUrl)
+	case source == ManifestiSourceLocal:
+		data, err = os.ReadFile(path.Join(absDataDir, constants.OutputManifestiListFilename))
+	case strings.HasPrefix(source, ManifestiSourceGitPrefix):
+		rev := strings.TrimPrefix(source, ManifestiSourceGitPrefix)
+		data, err = exec.Command("git", "-C", absDataDir, "show", rev+":./"+constants.OutputManifestiListFilename).Output()
+	default:
+		data, err = os.ReadFile(source)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("could not read manifesti source %s: %w", source, err)
+	}
+
+	mans, err := decodeManifesti(data)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode manifesti source %s: %w", source, err)
+	}
+	return mans, nil
+}
+
+func fetchRemoteManifesti(rawDataUrl string) ([]byte, error) {
+	remotePath, err := url.JoinPath(rawDataUrl, constants.OutputBaseFolder, "manifesti.json") // this is still the old filename
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := utils.HttpGet(remotePath)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", remotePath, res.Status)
+	}
+	return io.ReadAll(res.Body)
+}
+
+// decodeManifesti accepts both a manifesti list and the old format (degree -> course name -> location -> url)
+func decodeManifesti(data []byte) ([]scraper.Manifesto, error) {
+	var list []scraper.Manifesto
+	listErr := json.Unmarshal(data, &list)
+	if listErr == nil {
+		return list, nil
+	}
+
+	remote := RemoteManifesti{}
+	if err := json.Unmarshal(data, &remote.Data); err != nil {
+		return nil, listErr
+	}
+	return remote.ToList(), nil
+}
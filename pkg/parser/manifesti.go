@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/constants"
 	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/scraper"
 )
 
@@ -8,15 +9,22 @@ type (
 	degreeMap   = map[string][]scraper.Manifesto
 	locationMap = map[string]string
 	courseMap   = map[string]locationMap
+	// school -> academic year -> course name -> location -> url
+	schoolYearMap = map[string]map[uint16]courseMap
 )
 
 type ManifestiByDegreeType struct {
-	DegreeType string    `json:"degreeType"`
-	Data       courseMap `json:"data"`
+	SchemaVersion uint      `json:"schemaVersion"`
+	DegreeType    string    `json:"degreeType"`
+	Data          courseMap `json:"data"`
+	// manifesti without school and year (scraped by older versions) are only in Data
+	BySchoolYear schoolYearMap `json:"bySchoolYear"`
 }
 
 type ManifestiByCourse struct {
-	Data courseMap `json:"data"`
+	SchemaVersion uint          `json:"schemaVersion"`
+	Data          courseMap     `json:"data"`
+	BySchoolYear  schoolYearMap `json:"bySchoolYear"`
 }
 
 type RemoteManifesti struct {
@@ -28,7 +36,7 @@ func ParseManifestiByDegreeType(mans []scraper.Manifesto) []ManifestiByDegreeTyp
 	out := make([]ManifestiByDegreeType, 0, len(byDegType))
 	for dt, all := range groupByDegreeType(mans) {
 		data := groupByCourse(all)
-		m := ManifestiByDegreeType{DegreeType: dt, Data: data}
+		m := ManifestiByDegreeType{SchemaVersion: constants.OutputSchemaVersion, DegreeType: dt, Data: data, BySchoolYear: groupBySchoolYear(all)}
 		out = append(out, m)
 	}
 
@@ -38,7 +46,9 @@ func ParseManifestiByDegreeType(mans []scraper.Manifesto) []ManifestiByDegreeTyp
 func ParseManifestiByCourse(mans []scraper.Manifesto) ManifestiByCourse {
 	byDegType := groupByCourse(mans)
 	return ManifestiByCourse{
-		Data: byDegType,
+		SchemaVersion: constants.OutputSchemaVersion,
+		Data:          byDegType,
+		BySchoolYear:  groupBySchoolYear(mans),
 	}
 }
 
@@ -68,6 +78,29 @@ func groupByCourse(mans []scraper.Manifesto) courseMap {
 	return out
 }
 
+func groupBySchoolYear(mans []scraper.Manifesto) schoolYearMap {
+	out := make(schoolYearMap)
+
+	for _, m := range mans {
+		if m.School == "" || m.Year == 0 {
+			continue
+		}
+
+		if out[m.School] == nil {
+			out[m.School] = make(map[uint16]courseMap)
+		}
+		if out[m.School][m.Year] == nil {
+			out[m.School][m.Year] = make(courseMap)
+		}
+		if out[m.School][m.Year][m.Name] == nil {
+			out[m.School][m.Year][m.Name] = make(locationMap)
+		}
+		out[m.School][m.Year][m.Name][m.Location] = m.Url
+	}
+
+	return out
+}
+
 func (m *ManifestiByDegreeType) GetAll() []scraper.Manifesto {
 	out := make([]scraper.Manifesto, 0)
 	for ck, m2 := range m.Data {
@@ -144,11 +144,19 @@ func (p *RankingParser) parseMeritTablePage(html []byte) ([]StudentRow, error) {
 		// - if we DON'T HAVE the Id, we fill the s.Courses with the only course available from this table (obv only if the student can enroll)
 
 		statusText := p.getFieldByIndex(items, statusIdx, "")
+		s.Status = StatusUnknown
 		if statusText == "" {
 			slog.Warn("Merit row without status", "ranking-id", p.Ranking.Id, "position", s.Position)
 		} else {
-			lower := strings.ToLower(statusText)
-			s.CanEnroll = !strings.Contains(lower, "immatricolazione non consentita / enrolment is not possible")
+			s.StatusText = utils.SplitLabel(statusText)
+			s.Status = ParseEnrolmentStatus(s.StatusText.It, p.courseTitles)
+
+			var known bool
+			if s.CanEnroll, known = s.Status.CanEnroll(); !known {
+				slog.Warn("Merit row with unknown status", "ranking-id", p.Ranking.Id, "position", s.Position, "status", statusText)
+				lower := strings.ToLower(statusText)
+				s.CanEnroll = !strings.Contains(lower, "immatricolazione non consentita / enrolment is not possible")
+			}
 
 			if s.Courses == nil {
 				s.Courses = make([]CourseStatus, 0)
@@ -162,11 +170,11 @@ func (p *RankingParser) parseMeritTablePage(html []byte) ([]StudentRow, error) {
 					course := splitted[1]
 					title, location := getCourseTitleLocation(course)
 
-					s.Courses = append(s.Courses, CourseStatus{Title: title, Location: location, CanEnroll: true})
+					s.Courses = append(s.Courses, CourseStatus{Title: title, TitleLabel: utils.Label{It: title}, Location: location, CanEnroll: true, Status: s.Status, StatusText: s.StatusText})
 				} else {
 					// "<course name>"
 					title, location := getCourseTitleLocation(statusText)
-					s.Courses = append(s.Courses, CourseStatus{Title: title, Location: location, CanEnroll: true})
+					s.Courses = append(s.Courses, CourseStatus{Title: title, TitleLabel: utils.Label{It: title}, Location: location, CanEnroll: true, Status: s.Status, StatusText: s.StatusText})
 				}
 			}
 		}
@@ -177,6 +185,14 @@ func (p *RankingParser) parseMeritTablePage(html []byte) ([]StudentRow, error) {
 		// save to rowsById map
 		if s.Id != "" {
 			p.mu.Lock()
+			if prev, exists := p.Ranking.rowsById[s.Id]; exists {
+				p.Ranking.addWarning(RankingWarning{
+					Kind:      WarningDuplicateId,
+					StudentId: s.Id,
+					Position:  s.Position,
+					Message:   fmt.Sprintf("matricola hash already found at position %d", prev.Position),
+				})
+			}
 			p.Ranking.rowsById[s.Id] = s
 			p.mu.Unlock()
 		}
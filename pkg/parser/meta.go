@@ -0,0 +1,148 @@
+package parser
+
+import (
+	"log/slog"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/constants"
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/utils"
+	"github.com/PuerkitoBio/goquery"
+)
+
+type MetaLinkKind string
+
+const (
+	LinkByMerit    MetaLinkKind = "by_merit"
+	LinkById       MetaLinkKind = "by_id"
+	LinkByCourse   MetaLinkKind = "by_course"
+	LinkRegulation MetaLinkKind = "regulation" // calls for applications, regulations, ... (usually pdf)
+	LinkOther      MetaLinkKind = "other"
+)
+
+type MetaLink struct {
+	Kind  MetaLinkKind `json:"kind"`
+	Title utils.Label  `json:"title"`
+	Href  string       `json:"href"`
+	// saved html pages of the sub-index, only for by_merit, by_id and by_course
+	Pages int `json:"pages,omitempty"`
+}
+
+type Deadline struct {
+	Date utils.Date  `json:"date"`
+	Text utils.Label `json:"text"`
+}
+
+// RankingMeta is what the index page tells beyond the headings
+// (which are parsed into Year, School and Phase)
+type RankingMeta struct {
+	PublishedAt *utils.Date   `json:"publishedAt,omitempty"`
+	Deadlines   []Deadline    `json:"deadlines"`
+	Notes       []utils.Label `json:"notes"`
+	Links       []MetaLink    `json:"links"`
+}
+
+var (
+	metaDateRegex        = regexp.MustCompile(`\b\d{1,2}[/.-]\d{1,2}[/.-]\d{4}\b`)
+	metaPublicationRegex = regexp.MustCompile(`(?i)(pubblicat[aoe]|pubblicazione|published)\D{0,20}(\d{1,2}[/.-]\d{1,2}[/.-]\d{4})`)
+	metaDeadlineRegex    = regexp.MustCompile(`(?i)\b(entro|scadenza|termine|deadline)\b`)
+)
+
+func newRankingMeta() RankingMeta {
+	return RankingMeta{Deadlines: []Deadline{}, Notes: []utils.Label{}, Links: []MetaLink{}}
+}
+
+// parseMeta reads notes, dates and links of the index page.
+// Every element of .CenterBar which is not a heading (.intestazione), a sub-index (.titolo) or a link is a note.
+func (p *RankingParser) parseMeta(doc *goquery.Document) {
+	meta := newRankingMeta()
+
+	for _, s := range doc.Find(".CenterBar").Children().EachIter() {
+		if s.HasClass("intestazione") || s.HasClass("titolo") || s.Is("a") {
+			continue
+		}
+
+		if strings.TrimSpace(s.Text()) == strings.TrimSpace(s.Find("a").Text()) {
+			continue // only links, parsed below
+		}
+
+		label, err := utils.GetTextFragments(s)
+		if err != nil || label.It == "" {
+			continue
+		}
+		meta.Notes = append(meta.Notes, label)
+
+		if m := metaPublicationRegex.FindStringSubmatch(label.It); m != nil && meta.PublishedAt == nil {
+			if date, err := utils.ParseTableDate(normalizeDate(m[2])); err == nil {
+				meta.PublishedAt = &date
+			}
+		}
+
+		if metaDeadlineRegex.MatchString(label.It) {
+			for _, raw := range metaDateRegex.FindAllString(label.It, -1) {
+				date, err := utils.ParseTableDate(normalizeDate(raw))
+				if err != nil || (meta.PublishedAt != nil && date.Equal(meta.PublishedAt.Time)) {
+					continue
+				}
+				meta.Deadlines = append(meta.Deadlines, Deadline{Date: date, Text: label})
+			}
+		}
+	}
+
+	for _, s := range doc.Find(".CenterBar a[href]").EachIter() {
+		href, _ := s.Attr("href")
+		title, err := utils.GetTextFragments(s)
+		if err != nil {
+			continue
+		}
+
+		link := MetaLink{Kind: metaLinkKind(href, title.It), Title: title, Href: href}
+		if folder := metaLinkFolder(link.Kind); folder != "" {
+			entries, err := utils.GetEntriesInFolder(path.Join(p.rootDir, folder))
+			if err != nil {
+				slog.Warn("Could not count sub-index pages", "ranking-id", p.Ranking.Id, "folder", folder, "error", err)
+			}
+			link.Pages = len(entries)
+		}
+		meta.Links = append(meta.Links, link)
+	}
+
+	p.Ranking.Meta = meta
+}
+
+func metaLinkKind(href, title string) MetaLinkKind {
+	lowerHref, lowerTitle := strings.ToLower(href), strings.ToLower(title)
+
+	// same order of the scraper: the by_course suffix also ends with the by_id one
+	switch {
+	case strings.HasSuffix(href, constants.HtmlRankingUrl_IndexSuffix_ByCourse):
+		return LinkByCourse
+	case strings.HasSuffix(href, constants.HtmlRankingUrl_IndexSuffix_ById):
+		return LinkById
+	case strings.HasSuffix(href, constants.HtmlRankingUrl_IndexSuffix_ByMerit):
+		return LinkByMerit
+	case strings.HasSuffix(lowerHref, ".pdf"), strings.Contains(lowerTitle, "bando"), strings.Contains(lowerTitle, "regolamento"):
+		return LinkRegulation
+	default:
+		return LinkOther
+	}
+}
+
+func metaLinkFolder(kind MetaLinkKind) string {
+	switch kind {
+	case LinkByMerit:
+		return constants.OutputHtmlRanking_ByMeritFolder
+	case LinkById:
+		return constants.OutputHtmlRanking_ByIdFolder
+	case LinkByCourse:
+		return constants.OutputHtmlRanking_ByCourseFolder
+	default:
+		return ""
+	}
+}
+
+// normalizeDate converts the "." and "-" separators to "/", as in the tables
+func normalizeDate(s string) string {
+	return strings.NewReplacer(".", "/", "-", "/").Replace(s)
+}
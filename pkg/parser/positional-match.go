@@ -0,0 +1,217 @@
+package parser
+
+import (
+	"cmp"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+)
+
+const (
+	MatchingId         = "id"
+	MatchingPositional = "positional"
+
+	// max difference between two results to be considered equal (they are rounded to 2 decimals)
+	resultEpsilon = 0.005
+	// confidence penalty when the course table does not have the result column,
+	// so we can match only by position and enrolment
+	noResultPenalty = 0.8
+	// further penalty for rows not allowed to enroll without the result column,
+	// matched only by position and birth date
+	birthDateOnlyPenalty = 0.5
+	// the course table row data (birth date, sections, english result) is merged in the merit row
+	// only for unique matches at least this confident: a wrong guess would publish the data of a
+	// student on the row of another one
+	mergeMinConfidence = noResultPenalty
+)
+
+// matchCourseTablesByPosition joins course table rows to merit rows without the matricola id
+// (rankings before 2021), using a composite key:
+//   - position: course tables list students in merit order, so matches must be monotonic
+//   - result: if the course table has it, it must be equal
+//   - birth date: once a merit row got it from a course table, other course tables must agree
+//   - enrolment: a row allowed to enroll must match the merit row assigned to that course
+//
+// Rows not allowed to enroll in tables without the result column are matched only by position
+// and birth date (preferring merit rows whose birth date is already known): they get a lower
+// confidence and are always reported as ambiguous matches.
+//
+// Rows allowed to enroll are matched first (in every table), since they are the most constrained,
+// then the other rows are matched between their already matched neighbours.
+// Each match has a confidence (1 / number of candidates, lowered if the result is not available),
+// ambiguous and failed matches are reported as ranking warnings.
+// Ambiguous and birth date only matches attach just the course (with their low confidence),
+// the row data is merged only for unique matches of at least mergeMinConfidence.
+func (p *RankingParser) matchCourseTablesByPosition(tables []*courseTable) {
+	rows := p.Ranking.Rows
+	order := make([]int, len(rows)) // indexes of rows, sorted by merit position
+	for i := range rows {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int { return cmp.Compare(rows[a].Position, rows[b].Position) })
+
+	// deterministic output, tables are read concurrently
+	slices.SortFunc(tables, func(a, b *courseTable) int {
+		return cmp.Or(cmp.Compare(a.title, b.title), cmp.Compare(a.location, b.location))
+	})
+
+	// matchedAt[t][k] is the index in order of the merit row matched by tables[t].rows[k], -1 if not matched
+	matchedAt := make([][]int, len(tables))
+	for t, table := range tables {
+		slices.SortStableFunc(table.rows, func(a, b courseTableRow) int { return cmp.Compare(a.position, b.position) })
+		matchedAt[t] = slices.Repeat([]int{-1}, len(table.rows))
+	}
+
+	matched, ambiguous := 0, 0
+	for _, enrollPass := range []bool{true, false} {
+		for t, table := range tables {
+			for k, row := range table.rows {
+				if row.canEnroll != enrollPass {
+					continue
+				}
+
+				lo, hi := positionalBounds(matchedAt[t], k, len(order))
+				candidates := []int{} // indexes in order
+				for o := lo + 1; o < hi; o++ {
+					if isPositionalCandidate(&rows[order[o]], row, table) {
+						candidates = append(candidates, o)
+					}
+				}
+
+				birthDateOnly := !row.hasResult && !row.canEnroll
+				if birthDateOnly {
+					candidates = birthDateCandidates(rows, order, candidates, row)
+				}
+
+				if len(candidates) == 0 {
+					p.Ranking.addWarning(RankingWarning{
+						Kind:     WarningOrphanCourseRow,
+						Position: row.position,
+						Course:   table.title,
+						Message:  fmt.Sprintf("course table row could not be matched by position (%d candidates)", len(candidates)),
+					})
+					continue
+				}
+
+				confidence := 1 / float32(len(candidates))
+				if !row.hasResult {
+					confidence *= noResultPenalty
+				}
+				if birthDateOnly {
+					confidence *= birthDateOnlyPenalty
+				}
+
+				if len(candidates) > 1 || birthDateOnly {
+					ambiguous++
+					positions := make([]string, 0, len(candidates))
+					for _, o := range candidates {
+						positions = append(positions, fmt.Sprint(rows[order[o]].Position))
+					}
+					msg := fmt.Sprintf("course table row matches merit positions %s, course attached to the first, row data not merged", strings.Join(positions, ", "))
+					if birthDateOnly {
+						msg = fmt.Sprintf("course table row matched by position and birth date only (merit positions %s), course attached to the first, row data not merged", strings.Join(positions, ", "))
+					}
+					p.Ranking.addWarning(RankingWarning{
+						Kind:     WarningAmbiguousMatch,
+						Position: row.position,
+						Course:   table.title,
+						Message:  msg,
+					})
+				}
+
+				matchedAt[t][k] = candidates[0]
+				s := &rows[order[candidates[0]]]
+				if len(candidates) == 1 && !birthDateOnly && confidence >= mergeMinConfidence {
+					s.mergeCourseTableRow(row)
+				}
+				s.addPositionalCourse(CourseStatus{
+					Title:           table.title,
+					TitleLabel:      table.titleLabel,
+					Location:        table.location,
+					Position:        row.position,
+					CanEnroll:       row.canEnroll,
+					Status:          row.status,
+					StatusText:      row.statusText,
+					MatchConfidence: confidence,
+				})
+				matched++
+			}
+		}
+	}
+
+	slog.Info("Positional matching finished", "id", p.Ranking.Id, "matched", matched, "ambiguous", ambiguous)
+}
+
+// positionalBounds returns the (exclusive) range of indexes in order where the k-th row of a table
+// can be matched, given the rows of the same table already matched
+func positionalBounds(matchedAt []int, k int, n int) (int, int) {
+	lo, hi := -1, n
+	for j := k - 1; j >= 0; j-- {
+		if matchedAt[j] != -1 {
+			lo = matchedAt[j]
+			break
+		}
+	}
+
+	for j := k + 1; j < len(matchedAt); j++ {
+		if matchedAt[j] != -1 {
+			hi = matchedAt[j]
+			break
+		}
+	}
+
+	return lo, hi
+}
+
+// birthDateCandidates narrows the candidates of a row matched only by position and birth date:
+// without a birth date the row could be anyone, otherwise the merit rows with the same (already known)
+// birth date are preferred over the ones without it
+func birthDateCandidates(rows []StudentRow, order []int, candidates []int, row courseTableRow) []int {
+	if row.birthDate == nil {
+		return nil
+	}
+
+	known := slices.DeleteFunc(slices.Clone(candidates), func(o int) bool { return rows[order[o]].BirthDate == nil })
+	if len(known) > 0 {
+		return known
+	}
+	return candidates
+}
+
+func isPositionalCandidate(s *StudentRow, row courseTableRow, table *courseTable) bool {
+	if row.hasResult {
+		diff := s.Result - row.result
+		if diff > resultEpsilon || diff < -resultEpsilon {
+			return false
+		}
+	}
+
+	if s.BirthDate != nil && row.birthDate != nil && !s.BirthDate.Equal(row.birthDate.Time) {
+		return false
+	}
+
+	// the merit table status tells in which course the student is allowed to enroll
+	enrolled := slices.IndexFunc(s.Courses, func(c CourseStatus) bool { return c.CanEnroll })
+	if row.canEnroll {
+		return s.CanEnroll && enrolled != -1 && s.Courses[enrolled].Title == table.title && s.Courses[enrolled].Location == table.location
+	}
+
+	return true
+}
+
+// addPositionalCourse merges c with the course already known from the merit table status, if any
+func (s *StudentRow) addPositionalCourse(c CourseStatus) {
+	idx := slices.IndexFunc(s.Courses, func(sc CourseStatus) bool { return sc.Title == c.Title && sc.Location == c.Location })
+	if idx == -1 {
+		s.Courses = append(s.Courses, c)
+		return
+	}
+
+	if s.Courses[idx].CanEnroll {
+		// the merit table status is more detailed (e.g. booked instead of assigned)
+		c.CanEnroll = true
+		c.Status = s.Courses[idx].Status
+	}
+	s.Courses[idx] = c
+}
@@ -0,0 +1,181 @@
+package parser
+
+import (
+	"math"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/utils"
+)
+
+func testDate(s string) *utils.Date {
+	t, _ := time.Parse(utils.DateLayout, s)
+	return &utils.Date{Time: t}
+}
+
+func TestMatchCourseTablesByPosition(t *testing.T) {
+	enrolledIn := func(title string) []CourseStatus {
+		return []CourseStatus{{Title: title, Location: "MI", CanEnroll: true, Status: StatusAssigned}}
+	}
+
+	tests := []struct {
+		name           string
+		rows           []StudentRow
+		row            courseTableRow
+		wantPosition   uint16 // merit position of the matched row, 0 if not matched
+		wantConfidence float32
+		wantMerged     bool // the course table row data is merged in the matched merit row
+		wantWarnings   []WarningKind
+	}{
+		{
+			name: "unique enrolled row",
+			rows: []StudentRow{
+				{Position: 1, Result: 90},
+				{Position: 2, Result: 80, CanEnroll: true, Courses: enrolledIn("ING")},
+				{Position: 3, Result: 70},
+			},
+			row:            courseTableRow{position: 1, canEnroll: true},
+			wantPosition:   2,
+			wantConfidence: noResultPenalty,
+			wantMerged:     true,
+			wantWarnings:   []WarningKind{},
+		},
+		{
+			name: "unique by result",
+			rows: []StudentRow{
+				{Position: 1, Result: 90},
+				{Position: 2, Result: 80},
+				{Position: 3, Result: 70},
+			},
+			row:            courseTableRow{position: 1, result: 80, hasResult: true},
+			wantPosition:   2,
+			wantConfidence: 1,
+			wantMerged:     true,
+			wantWarnings:   []WarningKind{},
+		},
+		{
+			name: "ambiguous by result",
+			rows: []StudentRow{
+				{Position: 1, Result: 90},
+				{Position: 2, Result: 80},
+				{Position: 3, Result: 80},
+			},
+			row:            courseTableRow{position: 1, result: 80, hasResult: true},
+			wantPosition:   2,
+			wantConfidence: 0.5,
+			wantWarnings:   []WarningKind{WarningAmbiguousMatch},
+		},
+		{
+			name: "birth date only, known birth date",
+			rows: []StudentRow{
+				{Position: 1, Result: 90},
+				{Position: 2, Result: 80, BirthDate: testDate("2005-01-31")},
+				{Position: 3, Result: 70},
+			},
+			row:            courseTableRow{position: 1, birthDate: testDate("2005-01-31")},
+			wantPosition:   2,
+			wantConfidence: noResultPenalty * birthDateOnlyPenalty,
+			wantWarnings:   []WarningKind{WarningAmbiguousMatch},
+		},
+		{
+			name: "birth date only, guess between the rows without birth date",
+			rows: []StudentRow{
+				{Position: 1, Result: 90, BirthDate: testDate("2004-06-01")},
+				{Position: 2, Result: 80},
+				{Position: 3, Result: 70},
+			},
+			row:            courseTableRow{position: 1, birthDate: testDate("2005-01-31")},
+			wantPosition:   2,
+			wantConfidence: noResultPenalty * birthDateOnlyPenalty / 2,
+			wantWarnings:   []WarningKind{WarningAmbiguousMatch},
+		},
+		{
+			name: "orphan without result and birth date",
+			rows: []StudentRow{
+				{Position: 1, Result: 90},
+				{Position: 2, Result: 80},
+			},
+			row:          courseTableRow{position: 1},
+			wantWarnings: []WarningKind{WarningOrphanCourseRow},
+		},
+		{
+			name: "orphan without candidates",
+			rows: []StudentRow{
+				{Position: 1, Result: 90},
+				{Position: 2, Result: 80},
+			},
+			row:          courseTableRow{position: 1, result: 75, hasResult: true},
+			wantWarnings: []WarningKind{WarningOrphanCourseRow},
+		},
+		{
+			name: "orphan enrolled row in another course",
+			rows: []StudentRow{
+				{Position: 1, Result: 90, CanEnroll: true, Courses: enrolledIn("OTHER")},
+			},
+			row:          courseTableRow{position: 1, canEnroll: true},
+			wantWarnings: []WarningKind{WarningOrphanCourseRow},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &RankingParser{Ranking: *NewRanking()}
+			p.Ranking.Rows = tt.rows
+			row := tt.row
+			row.englishResult, row.hasEnglishResult = 42, true
+			p.matchCourseTablesByPosition([]*courseTable{{title: "ING", location: "MI", rows: []courseTableRow{row}}})
+
+			gotPosition, gotConfidence, gotMerged := uint16(0), float32(0), false
+			for _, r := range p.Ranking.Rows {
+				gotMerged = gotMerged || r.EnglishResult == 42
+				idx := slices.IndexFunc(r.Courses, func(c CourseStatus) bool { return c.Title == "ING" && c.MatchConfidence > 0 })
+				if idx != -1 {
+					gotPosition, gotConfidence = r.Position, r.Courses[idx].MatchConfidence
+				}
+			}
+
+			if gotPosition != tt.wantPosition {
+				t.Errorf("matched merit position %d, want %d", gotPosition, tt.wantPosition)
+			}
+			if math.Abs(float64(gotConfidence-tt.wantConfidence)) > 1e-6 {
+				t.Errorf("confidence %v, want %v", gotConfidence, tt.wantConfidence)
+			}
+			if gotMerged != tt.wantMerged {
+				t.Errorf("row data merged %v, want %v", gotMerged, tt.wantMerged)
+			}
+
+			gotWarnings := []WarningKind{}
+			for _, w := range p.Ranking.Warnings {
+				gotWarnings = append(gotWarnings, w.Kind)
+			}
+			if !slices.Equal(gotWarnings, tt.wantWarnings) {
+				t.Errorf("warnings %v, want %v", gotWarnings, tt.wantWarnings)
+			}
+		})
+	}
+}
+
+func TestMatchCourseTablesByPositionBirthDate(t *testing.T) {
+	// the enrolled row gives its birth date to the merit row, then the other table
+	// matches the same student by position and birth date
+	p := &RankingParser{Ranking: *NewRanking()}
+	p.Ranking.Rows = []StudentRow{
+		{Position: 1, Result: 90},
+		{Position: 2, Result: 80, CanEnroll: true, Courses: []CourseStatus{{Title: "A", Location: "MI", CanEnroll: true}}},
+		{Position: 3, Result: 70},
+	}
+
+	p.matchCourseTablesByPosition([]*courseTable{
+		{title: "A", location: "MI", rows: []courseTableRow{{position: 1, canEnroll: true, birthDate: testDate("2005-01-31")}}},
+		{title: "B", location: "MI", rows: []courseTableRow{{position: 1, birthDate: testDate("2005-01-31")}}},
+	})
+
+	row := p.Ranking.Rows[1]
+	if len(row.Courses) != 2 || row.Courses[1].Title != "B" {
+		t.Fatalf("merit position 2 has courses %+v, want A and B", row.Courses)
+	}
+	if row.BirthDate == nil || row.BirthDate.Format(utils.DateLayout) != "2005-01-31" {
+		t.Errorf("birth date %v, want 2005-01-31", row.BirthDate)
+	}
+}
@@ -0,0 +1,43 @@
+package parser
+
+import (
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/privacy"
+)
+
+// ApplyBirthDatePolicy generalises or drops the rows' birth date.
+// The parser always reads the full date, so this must be called right before writing
+// any output containing student rows (rankings, exports, ...), in order to apply
+// the same policy everywhere.
+func (r *Ranking) ApplyBirthDatePolicy(policy privacy.BirthDatePolicy) {
+	for i := range r.Rows {
+		r.Rows[i].ApplyBirthDatePolicy(policy, r.Year)
+	}
+}
+
+// ApplyBirthDatePolicy generalises a single row, rankingYear is needed by the age-bucket policy.
+// It also works on rows already generalised (e.g. read from the output),
+// but it can only generalise further: an age group never becomes a year of birth
+func (s *StudentRow) ApplyBirthDatePolicy(policy privacy.BirthDatePolicy, rankingYear uint16) {
+	if policy == privacy.BirthDateKeep {
+		return
+	}
+
+	birthYear := s.BirthYear
+	if s.BirthDate != nil {
+		birthYear = uint16(s.BirthDate.Year())
+	}
+	s.BirthDate = nil
+
+	switch policy {
+	case privacy.BirthDateYearOnly:
+		s.BirthYear = birthYear
+	case privacy.BirthDateAgeBucket:
+		s.BirthYear = 0
+		if birthYear != 0 {
+			s.AgeGroup = privacy.AgeBucket(birthYear, rankingYear)
+		}
+	case privacy.BirthDateDrop:
+		s.BirthYear = 0
+		s.AgeGroup = ""
+	}
+}
@@ -16,15 +16,27 @@ import (
 )
 
 type CourseStatus struct {
-	Title     string `json:"title"`
-	Location  string `json:"location"`
-	Position  uint16 `json:"position"`
-	CanEnroll bool   `json:"canEnroll"`
+	Title      string      `json:"title"`
+	TitleLabel utils.Label `json:"titleLabel"`
+	Location   string      `json:"location"`
+	Position   uint16      `json:"position"`
+	CanEnroll  bool        `json:"canEnroll"` // kept for compatibility, derived from Status when known
+
+	Status     EnrolmentStatus `json:"status"`
+	StatusText utils.Label     `json:"statusText"` // raw text of the course table cell (of the merit table status, if not available)
+
+	// only for rankings without matricola, see positional-match.go
+	MatchConfidence float32 `json:"matchConfidence,omitempty"`
 }
 
 type StudentRow struct {
-	Id        string `json:"id"`
-	BirthDate string `json:"birthDate,omitempty"`
+	Id string `json:"id"`
+
+	// the birth date is published according to the output birth date policy (see privacy.go):
+	// at most one of BirthDate, BirthYear and AgeGroup is set
+	BirthDate *utils.Date `json:"birthDate,omitempty"`
+	BirthYear uint16      `json:"birthYear,omitempty"`
+	AgeGroup  string      `json:"ageGroup,omitempty"`
 
 	Position uint16 `json:"position"`
 
@@ -38,6 +50,10 @@ type StudentRow struct {
 	// if CanEnroll is false, we dont give a fuck
 	CanEnroll bool `json:"canEnroll"`
 
+	Status EnrolmentStatus `json:"status"`
+	// raw text of the merit table status column
+	StatusText utils.Label `json:"statusText"`
+
 	Courses []CourseStatus `json:"courses"`
 
 	Result          float32            `json:"result"`
@@ -47,15 +63,27 @@ type StudentRow struct {
 }
 
 type Ranking struct {
+	SchemaVersion uint `json:"schemaVersion"`
+
 	Id     string `json:"id"`
 	School string `json:"school"`
 	Year   uint16 `json:"year"`
 
 	// Stats   Stats
 	Phase   Phase               `json:"phase"`
+	Meta    RankingMeta         `json:"meta"`
 	Courses map[string][]string `json:"courses"`
 	Rows    []StudentRow        `json:"rows"`
 
+	// how course tables are matched to merit table rows: MatchingId or MatchingPositional
+	Matching string `json:"matching"`
+
+	// Italian and English texts, see labels.go
+	Labels RankingLabels `json:"labels"`
+
+	// inconsistencies between merit and course tables, see validation.go
+	Warnings []RankingWarning `json:"warnings"`
+
 	rowsById map[string]StudentRow
 }
 
@@ -64,13 +92,21 @@ type RankingParser struct {
 	reader  writer.Writer[[]byte]
 	Ranking Ranking
 
+	// titles of the course tables, read before the merit table to recognise
+	// the statuses which are just a course name
+	courseTitles []string
+
 	mu sync.Mutex
 }
 
 func NewRanking() *Ranking {
 	return &Ranking{
-		rowsById: map[string]StudentRow{},
-		Courses:  map[string][]string{},
+		SchemaVersion: constants.OutputSchemaVersion,
+		rowsById:      map[string]StudentRow{},
+		Warnings:      []RankingWarning{},
+		Courses:       map[string][]string{},
+		Labels:        newRankingLabels(),
+		Meta:          newRankingMeta(),
 	}
 }
 
@@ -110,6 +146,7 @@ func (p *RankingParser) Parse() *Ranking {
 	// run MERIT parser BEFORE COURSE parser
 	//
 	// MERIT
+	p.courseTitles = readCourseTitles(coursesTablePages)
 	err = p.parseMeritTable(meritTablePages)
 	if err != nil {
 		slog.Error("Could not parse Ranking merit table pages", "folder-path", path.Join(p.rootDir, constants.OutputHtmlRanking_ByMeritFolder), "error", err)
@@ -124,6 +161,11 @@ func (p *RankingParser) Parse() *Ranking {
 	}
 
 	p.Ranking.ensureSorting()
+	p.Ranking.fillLabels()
+	p.Ranking.validate()
+	if len(p.Ranking.Warnings) > 0 {
+		slog.Warn("Ranking has consistency warnings", "id", p.Ranking.Id, "counts", p.Ranking.WarningCounts())
+	}
 
 	return &p.Ranking
 }
@@ -135,18 +177,25 @@ func (p *RankingParser) parseIndex(html []byte) error {
 	}
 
 	headings := make([]string, 5)
+	labels := make([]utils.Label, 5)
 	for i, s := range doc.Find(".CenterBar .intestazione").EachIter() {
 		text, err := utils.GetFirstTextFragment(s)
 		if err != nil {
 			return err
 		}
 
+		label, err := utils.GetTextFragments(s)
+		if err != nil {
+			return err
+		}
+
 		if i >= 5 {
 			slog.Warn("Something is wrong with the index parsing, we got a 5-indexed element '.CenterBar .intestazione', maybe Polimi changed something. Please check", "heading index", i, "text", text)
 			break
 		}
 
 		headings[i] = text
+		labels[i] = label
 	}
 
 	if err = p.Ranking.parseYear(headings[1]); err != nil {
@@ -162,6 +211,9 @@ func (p *RankingParser) parseIndex(html []byte) error {
 	if err = p.Ranking.Phase.ParseText(headings[3], &p.Ranking); err != nil {
 		return fmt.Errorf("Could not parse phase. Phase raw string: '%s'. Error: %w", strings.ToLower(headings[3]), err)
 	}
+	p.Ranking.Labels.Phase = labels[3]
+
+	p.parseMeta(doc)
 
 	return nil
 }
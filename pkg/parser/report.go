@@ -0,0 +1,52 @@
+package parser
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/constants"
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/writer"
+)
+
+type parseReportEntry struct {
+	Parsed   bool                `json:"parsed"`
+	Rows     int                 `json:"rows"`
+	Warnings map[WarningKind]int `json:"warnings"`
+}
+
+// ParseReport summarizes the outcome of a parser run, one entry per ranking id
+type ParseReport struct {
+	SchemaVersion uint                        `json:"schemaVersion"`
+	Rankings      map[string]parseReportEntry `json:"rankings"`
+
+	mu sync.Mutex
+}
+
+func NewParseReport() *ParseReport {
+	return &ParseReport{
+		SchemaVersion: constants.OutputSchemaVersion,
+		Rankings:      map[string]parseReportEntry{},
+	}
+}
+
+// Add records the outcome of parsing ranking id; ranking is nil if it could not be parsed
+func (r *ParseReport) Add(id string, ranking *Ranking) {
+	entry := parseReportEntry{Parsed: ranking != nil, Warnings: map[WarningKind]int{}}
+	if ranking != nil {
+		entry.Rows = len(ranking.Rows)
+		entry.Warnings = ranking.WarningCounts()
+	}
+
+	r.mu.Lock()
+	r.Rankings[id] = entry
+	r.mu.Unlock()
+}
+
+func (r *ParseReport) Write(absOutDir string, indent bool) error {
+	w := writer.NewWriter[*ParseReport](absOutDir)
+	if err := w.JsonWrite(constants.OutputParseReportFilename, r, indent); err != nil {
+		return fmt.Errorf("error while writing parse report, error: %w", err)
+	}
+
+	return nil
+}
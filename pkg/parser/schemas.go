@@ -0,0 +1,89 @@
+package parser
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/constants"
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/schema"
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/writer"
+)
+
+const schemasBaseId = "https://rankings.polinetwork.org/schemas/"
+
+// OutputSchemas returns the schema of every file written by the parser in the output folder.
+// Patterns are relative to the output folder.
+func OutputSchemas() []schema.Entry {
+	entries := []schema.Entry{
+		newSchemaEntry("ranking", path.Join(constants.OutputParsedRankingsFolder, "*.json"), Ranking{}),
+		newSchemaEntry("admission", path.Join(constants.OutputAdmissionFolder, "*.json"), AdmissionModel{}),
+		newSchemaEntry("ranking-stats", path.Join(constants.OutputRankingsStatsFolder, "*.json"), RankingStats{}),
+		newSchemaEntry("parse-report", constants.OutputParseReportFilename, ParseReport{}),
+		newIndexSchemaEntry("index-by-school-year", path.Join(constants.OutputIndexesFolder, constants.OutputIndexBySchoolYearFilename), bySchoolYear{}),
+		newIndexSchemaEntry("index-by-year-school", path.Join(constants.OutputIndexesFolder, constants.OutputIndexByYearSchoolFilename), byYearSchool{}),
+		newIndexSchemaEntry("index-by-student-id-hash", path.Join(constants.OutputIndexesFolder, constants.OutputIndexByStudentIdHashFilename), byStudentIdHash{}),
+		newIndexSchemaEntry("index-diffs", path.Join(constants.OutputIndexesFolder, constants.OutputIndexDiffsFilename), byDiffSchoolYear{}),
+		newIndexSchemaEntry("index-manifesti-availability", path.Join(constants.OutputIndexesFolder, constants.OutputIndexManifestiAvailabilityFilename), manifestiAvailability{}),
+		newSchemaEntry("ranking-diff", path.Join(constants.OutputDiffsFolder, "*.json"), RankingDiff{}),
+		newSchemaEntry("seat-flow", path.Join(constants.OutputSeatFlowFolder, "*.json"), SeatFlow{}),
+		newSchemaEntry("study-plan", path.Join(constants.OutputParsedManifestiFolder, constants.OutputStudyPlansFolder, "*.json"), StudyPlan{}),
+		// all.json must come before the generic degree type pattern
+		newSchemaEntry("manifesti-by-course", path.Join(constants.OutputParsedManifestiFolder, constants.OutputParsedManifestiAllFilename), ManifestiByCourse{}),
+		newSchemaEntry("manifesti-by-degree-type", path.Join(constants.OutputParsedManifestiFolder, "*.json"), ManifestiByDegreeType{}),
+	}
+
+	// the published schemas themselves are not validated
+	entries = append(entries, schema.Entry{Name: "schema", Pattern: path.Join(constants.OutputSchemasFolder, "*.json")})
+	return entries
+}
+
+func newSchemaEntry(name, pattern string, v any) schema.Entry {
+	s := schema.Generate(v, schemasBaseId+name+".schema.json", name)
+	s.Description = fmt.Sprintf("Output schema version %d", constants.OutputSchemaVersion)
+	if versionProp, ok := s.Properties["schemaVersion"]; ok {
+		versionProp.Const = constants.OutputSchemaVersion
+	}
+
+	return schema.Entry{Name: name, Pattern: pattern, Schema: s}
+}
+
+// newIndexSchemaEntry is the schema of an indexFile: the schema of data, with schemaVersion next to its keys
+func newIndexSchemaEntry(name, pattern string, data any) schema.Entry {
+	e := newSchemaEntry(name, pattern, data)
+	s := e.Schema
+	s.Type = "object"
+	if s.Properties == nil {
+		s.Properties = map[string]*schema.Schema{}
+	}
+	s.Properties["schemaVersion"] = &schema.Schema{Type: "integer", Const: constants.OutputSchemaVersion}
+	s.Required = append(s.Required, "schemaVersion")
+
+	// maps with numeric keys (e.g. years) restrict the property names
+	if s.PropertyNames != nil && s.PropertyNames.Pattern != "" {
+		s.PropertyNames.Pattern = fmt.Sprintf("^(?:schemaVersion|%s)$", strings.TrimSuffix(strings.TrimPrefix(s.PropertyNames.Pattern, "^"), "$"))
+	}
+
+	return e
+}
+
+// WriteSchemas publishes the output schemas as <name>.schema.json files in absOutDir
+func WriteSchemas(absOutDir string) error {
+	w := writer.NewWriter[any](absOutDir)
+	for _, e := range OutputSchemas() {
+		if e.Schema == nil {
+			continue
+		}
+
+		data, err := e.Schema.Json()
+		if err != nil {
+			return err
+		}
+
+		if err := w.Write(e.Name+".schema.json", data); err != nil {
+			return fmt.Errorf("error while writing schema %s: %w", e.Name, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,273 @@
+package parser
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/constants"
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/utils"
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/writer"
+)
+
+type SankeyNodeKind string
+
+const (
+	NodeCourse     SankeyNodeKind = "course"     // allowed to enroll in the course
+	NodeUnassigned SankeyNodeKind = "unassigned" // in the ranking, but not allowed to enroll
+	NodeNew        SankeyNodeKind = "new"        // not in the previous ranking
+	NodeLeft       SankeyNodeKind = "left"       // not in the next ranking (enrolled, withdrawn, ...)
+)
+
+// CoursePhase is the state of a course's seats in one ranking of the stream
+type CoursePhase struct {
+	RankingId     string  `json:"rankingId"`
+	Phase         Phase   `json:"phase"`
+	IsRipescaggio bool    `json:"isRipescaggio"`
+	Assigned      int     `json:"assigned"`
+	Cutoff        *Cutoff `json:"cutoff,omitempty"`
+
+	// only if the stream is tracked (all rows have the student id)
+	Kept          int `json:"kept"`          // assigned to this course also in the previous ranking
+	NewlyAssigned int `json:"newlyAssigned"` // not assigned to this course in the previous ranking
+	Freed         int `json:"freed"`         // assigned to this course in the previous ranking, not anymore
+}
+
+type CourseTimeline struct {
+	CourseRef
+	Phases []CoursePhase `json:"phases"`
+}
+
+type SankeyNode struct {
+	Id     string         `json:"id"`
+	Step   int            `json:"step"` // index of the ranking in the stream
+	Kind   SankeyNodeKind `json:"kind"`
+	Course *CourseRef     `json:"course,omitempty"`
+}
+
+type SankeyLink struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Value  int    `json:"value"`
+}
+
+type Sankey struct {
+	Nodes []SankeyNode `json:"nodes"`
+	Links []SankeyLink `json:"links"`
+}
+
+type SeatFlowStream struct {
+	Language  string       `json:"language"`
+	IsExtraEu bool         `json:"isExtraEu"`
+	Rankings  []RankingRef `json:"rankings"` // sorted by phase
+
+	// false if some rows don't have the student id (rankings before 2021):
+	// flows between rankings can't be reconstructed, so only Assigned and Cutoff are available
+	Tracked bool             `json:"tracked"`
+	Courses []CourseTimeline `json:"courses"`
+	Sankey  Sankey           `json:"sankey"`
+}
+
+// SeatFlow reconstructs how the seats of each course filled across the phases of a school year
+type SeatFlow struct {
+	SchemaVersion uint             `json:"schemaVersion"`
+	School        string           `json:"school"`
+	Year          uint16           `json:"year"`
+	Streams       []SeatFlowStream `json:"streams"`
+}
+
+func (f *SeatFlow) Filename() string {
+	return utils.MakeFilename(fmt.Sprintf("%s %d", f.School, f.Year), ".json")
+}
+
+// SeatFlows returns the seat flow of every school year in rankings
+func SeatFlows(rankings []Ranking) []*SeatFlow {
+	flows := make([]*SeatFlow, 0)
+	for _, s := range phaseStreams(rankings) {
+		if len(flows) == 0 || flows[len(flows)-1].School != s.school || flows[len(flows)-1].Year != s.year {
+			flows = append(flows, &SeatFlow{SchemaVersion: constants.OutputSchemaVersion, School: s.school, Year: s.year, Streams: []SeatFlowStream{}})
+		}
+
+		f := flows[len(flows)-1]
+		f.Streams = append(f.Streams, seatFlowStream(s))
+	}
+
+	return flows
+}
+
+func WriteSeatFlows(absOutDir string, flows []*SeatFlow, indent bool) error {
+	w := writer.NewWriter[*SeatFlow](absOutDir)
+	for _, f := range flows {
+		if err := w.JsonWrite(f.Filename(), f, indent); err != nil {
+			return fmt.Errorf("error while writing seat flow %s: %w", f.Filename(), err)
+		}
+	}
+
+	return nil
+}
+
+// studentState is the sankey node key of a student in a ranking
+func studentState(row StudentRow) (SankeyNodeKind, CourseRef) {
+	if c, ok := row.EnrolledCourse(); ok {
+		return NodeCourse, CourseRef{Title: c.Title, Location: c.Location}
+	}
+	return NodeUnassigned, CourseRef{}
+}
+
+func seatFlowStream(s phaseStream) SeatFlowStream {
+	out := SeatFlowStream{Language: s.language, IsExtraEu: s.isExtraEu, Tracked: true, Rankings: []RankingRef{}}
+
+	rowsById := make([]map[string]StudentRow, len(s.rankings))
+	for i, r := range s.rankings {
+		out.Rankings = append(out.Rankings, RankingRef{Id: r.Id, Phase: r.Phase})
+		rows, skipped := rowsByStudentId(r)
+		rowsById[i] = rows
+		out.Tracked = out.Tracked && skipped == 0 && len(r.Rows) > 0
+	}
+
+	out.Courses = courseTimelines(s.rankings, rowsById, out.Tracked)
+	out.Sankey = Sankey{Nodes: []SankeyNode{}, Links: []SankeyLink{}}
+	if out.Tracked {
+		out.Sankey = seatFlowSankey(rowsById)
+	}
+
+	return out
+}
+
+func courseTimelines(rankings []*Ranking, rowsById []map[string]StudentRow, tracked bool) []CourseTimeline {
+	courses := map[CourseRef]*CourseTimeline{}
+	for _, r := range rankings {
+		for _, row := range r.Rows {
+			if kind, course := studentState(row); kind == NodeCourse {
+				if _, ok := courses[course]; !ok {
+					courses[course] = &CourseTimeline{CourseRef: course, Phases: []CoursePhase{}}
+				}
+			}
+		}
+	}
+
+	for i, r := range rankings {
+		phases := map[CourseRef]*CoursePhase{}
+		for ref := range courses {
+			phases[ref] = &CoursePhase{
+				RankingId:     r.Id,
+				Phase:         r.Phase,
+				IsRipescaggio: strings.Contains(strings.ToLower(r.Phase.Stripped), "ripescaggio"),
+			}
+		}
+
+		for _, row := range r.Rows {
+			if kind, course := studentState(row); kind == NodeCourse {
+				phases[course].Assigned++
+				phases[course].Cutoff = worseCutoff(phases[course].Cutoff, row)
+			}
+		}
+
+		if tracked {
+			for id, row := range rowsById[i] {
+				kind, course := studentState(row)
+				if kind != NodeCourse {
+					continue
+				}
+
+				prevKind, prevCourse := NodeNew, CourseRef{}
+				if i > 0 {
+					if prev, ok := rowsById[i-1][id]; ok {
+						prevKind, prevCourse = studentState(prev)
+					}
+				}
+
+				if prevKind == NodeCourse && prevCourse == course {
+					phases[course].Kept++
+				} else {
+					phases[course].NewlyAssigned++
+				}
+			}
+
+			if i > 0 {
+				for id, prev := range rowsById[i-1] {
+					prevKind, prevCourse := studentState(prev)
+					if prevKind != NodeCourse {
+						continue
+					}
+
+					row, ok := rowsById[i][id]
+					kind, course := studentState(row)
+					if !ok || kind != NodeCourse || course != prevCourse {
+						phases[prevCourse].Freed++
+					}
+				}
+			}
+		}
+
+		for ref, p := range phases {
+			courses[ref].Phases = append(courses[ref].Phases, *p)
+		}
+	}
+
+	out := make([]CourseTimeline, 0, len(courses))
+	for _, c := range courses {
+		out = append(out, *c)
+	}
+	slices.SortFunc(out, func(a, b CourseTimeline) int {
+		return cmp.Or(cmp.Compare(a.Title, b.Title), cmp.Compare(a.Location, b.Location))
+	})
+
+	return out
+}
+
+func seatFlowSankey(rowsById []map[string]StudentRow) Sankey {
+	sankey := Sankey{Nodes: []SankeyNode{}, Links: []SankeyLink{}}
+	nodes := map[string]bool{}
+	addNode := func(step int, kind SankeyNodeKind, course CourseRef) string {
+		id := fmt.Sprintf("%d:%s", step, kind)
+		if kind == NodeCourse {
+			id = fmt.Sprintf("%d:%s (%s)", step, course.Title, course.Location)
+		}
+
+		if !nodes[id] {
+			nodes[id] = true
+			node := SankeyNode{Id: id, Step: step, Kind: kind}
+			if kind == NodeCourse {
+				node.Course = &course
+			}
+			sankey.Nodes = append(sankey.Nodes, node)
+		}
+
+		return id
+	}
+
+	for i := 1; i < len(rowsById); i++ {
+		values := map[[2]string]int{}
+		for id := range mapUnion(rowsById[i-1], rowsById[i]) {
+			prev, inPrev := rowsById[i-1][id]
+			row, inCur := rowsById[i][id]
+
+			source := addNode(i-1, NodeNew, CourseRef{})
+			if inPrev {
+				kind, course := studentState(prev)
+				source = addNode(i-1, kind, course)
+			}
+
+			target := addNode(i, NodeLeft, CourseRef{})
+			if inCur {
+				kind, course := studentState(row)
+				target = addNode(i, kind, course)
+			}
+
+			values[[2]string{source, target}]++
+		}
+
+		for link, value := range values {
+			sankey.Links = append(sankey.Links, SankeyLink{Source: link[0], Target: link[1], Value: value})
+		}
+	}
+
+	slices.SortFunc(sankey.Nodes, func(a, b SankeyNode) int { return cmp.Or(cmp.Compare(a.Step, b.Step), cmp.Compare(a.Id, b.Id)) })
+	slices.SortFunc(sankey.Links, func(a, b SankeyLink) int {
+		return cmp.Or(cmp.Compare(a.Source, b.Source), cmp.Compare(a.Target, b.Target))
+	})
+
+	return sankey
+}
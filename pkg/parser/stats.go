@@ -0,0 +1,142 @@
+package parser
+
+import (
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/constants"
+)
+
+const maxHistogramBuckets = 20
+
+// percentiles published for every distribution
+var statsPercentiles = []int{10, 25, 50, 75, 90, 95, 99}
+
+// Histogram buckets are [Start + i*Width, Start + (i+1)*Width), the last one includes the max
+type Histogram struct {
+	Start  float32 `json:"start"`
+	Width  float32 `json:"width"`
+	Counts []int   `json:"counts"`
+}
+
+type Distribution struct {
+	Count       int                `json:"count"`
+	Min         float32            `json:"min"`
+	Max         float32            `json:"max"`
+	Mean        float32            `json:"mean"`
+	Percentiles map[string]float32 `json:"percentiles"` // "p10", "p25", ... "p99"
+	Histogram   Histogram          `json:"histogram"`
+}
+
+type ScoreStats struct {
+	Result   Distribution            `json:"result"`
+	Sections map[string]Distribution `json:"sections"` // StudentRow.SectionsResults keys
+}
+
+// RankingStats summarizes the scores of a ranking, so the frontend can show
+// "where do I stand" charts without downloading all the rows
+type RankingStats struct {
+	SchemaVersion uint   `json:"schemaVersion"`
+	Id            string `json:"id"`
+
+	All ScoreStats `json:"all"`
+	// keyed by Ranking.Courses keys, with the students subscribed to the course (any location)
+	Courses map[string]ScoreStats `json:"courses"`
+}
+
+func NewRankingStats(r *Ranking) *RankingStats {
+	stats := &RankingStats{
+		SchemaVersion: constants.OutputSchemaVersion,
+		Id:            r.Id,
+		All:           newScoreStats(r.Rows),
+		Courses:       map[string]ScoreStats{},
+	}
+
+	for title := range r.Courses {
+		rows := make([]StudentRow, 0)
+		for _, row := range r.Rows {
+			if slices.ContainsFunc(row.Courses, func(c CourseStatus) bool { return c.Title == title }) {
+				rows = append(rows, row)
+			}
+		}
+		stats.Courses[title] = newScoreStats(rows)
+	}
+
+	return stats
+}
+
+func newScoreStats(rows []StudentRow) ScoreStats {
+	results := make([]float32, 0, len(rows))
+	sections := map[string][]float32{}
+	for _, row := range rows {
+		results = append(results, row.Result)
+		for name, value := range row.SectionsResults {
+			sections[name] = append(sections[name], value)
+		}
+	}
+
+	stats := ScoreStats{Result: newDistribution(results), Sections: map[string]Distribution{}}
+	for name, values := range sections {
+		stats.Sections[name] = newDistribution(values)
+	}
+
+	return stats
+}
+
+func newDistribution(values []float32) Distribution {
+	d := Distribution{Count: len(values), Percentiles: map[string]float32{}, Histogram: Histogram{Counts: []int{}}}
+	if len(values) == 0 {
+		return d
+	}
+
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+	d.Min, d.Max = sorted[0], sorted[len(sorted)-1]
+
+	sum := 0.0
+	for _, v := range sorted {
+		sum += float64(v)
+	}
+	d.Mean = float32(math.Round(sum/float64(len(sorted))*100) / 100)
+
+	for _, p := range statsPercentiles {
+		// nearest-rank method
+		rank := int(math.Ceil(float64(p) / 100 * float64(len(sorted))))
+		d.Percentiles[fmt.Sprintf("p%d", p)] = sorted[max(rank-1, 0)]
+	}
+
+	d.Histogram = newHistogram(sorted)
+	return d
+}
+
+// newHistogram uses a "nice" bucket width (1, 2, 5, 10, ... or 0.5, 0.25, ...),
+// so buckets are easy to read, with about maxHistogramBuckets buckets
+func newHistogram(sorted []float32) Histogram {
+	lo, hi := float64(sorted[0]), float64(sorted[len(sorted)-1])
+	width := niceWidth((hi - lo) / maxHistogramBuckets)
+	start := math.Floor(lo/width) * width
+	buckets := max(int(math.Floor((hi-start)/width))+1, 1)
+
+	h := Histogram{Start: float32(start), Width: float32(width), Counts: make([]int, buckets)}
+	for _, v := range sorted {
+		idx := min(int(math.Floor((float64(v)-start)/width)), buckets-1)
+		h.Counts[idx]++
+	}
+
+	return h
+}
+
+func niceWidth(raw float64) float64 {
+	if raw <= 0 {
+		return 1
+	}
+
+	magnitude := math.Pow(10, math.Floor(math.Log10(raw)))
+	for _, step := range []float64{1, 2, 2.5, 5, 10} {
+		if raw <= step*magnitude {
+			return step * magnitude
+		}
+	}
+	return 10 * magnitude
+}
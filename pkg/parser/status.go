@@ -0,0 +1,67 @@
+package parser
+
+import (
+	"slices"
+	"strings"
+)
+
+// EnrolmentStatus is the typed version of the status column of merit and course tables.
+// The raw text is kept next to it (StudentRow.StatusText, CourseStatus.StatusText),
+// since some rankings have their own statuses, parsed as StatusUnknown.
+type EnrolmentStatus string
+
+const (
+	StatusAssigned   EnrolmentStatus = "assigned"    // "Assegnato - <course>", "<course>", "Si"
+	StatusBooked     EnrolmentStatus = "booked"      // "Prenotato - <course>"
+	StatusWaiting    EnrolmentStatus = "waiting"     // "Attesa - immatricolazione non consentita"
+	StatusNotAllowed EnrolmentStatus = "not_allowed" // "Immatricolazione non consentita", "No"
+	StatusWithdrawn  EnrolmentStatus = "withdrawn"   // "Rinuncia", "Rinunciatario"
+	StatusUnknown    EnrolmentStatus = "unknown"
+)
+
+// CanEnroll tells if the student is allowed to enroll, ok is false for StatusUnknown
+func (s EnrolmentStatus) CanEnroll() (canEnroll bool, ok bool) {
+	switch s {
+	case StatusAssigned, StatusBooked:
+		return true, true
+	case StatusWaiting, StatusNotAllowed, StatusWithdrawn:
+		return false, true
+	default:
+		return false, false
+	}
+}
+
+// ParseEnrolmentStatus works for the merit table status column of every school
+// (ing, urb, des: "immatricolazione", arch: "stato") and for the course tables enrolment column.
+// courseTitles are the titles of the ranking's course tables, since older rankings
+// only write the course name of the assigned students.
+func ParseEnrolmentStatus(raw string, courseTitles []string) EnrolmentStatus {
+	lower := strings.ToLower(strings.TrimSpace(raw))
+	prefix, _, _ := strings.Cut(lower, " - ")
+
+	switch {
+	case lower == "":
+		return StatusUnknown
+	case strings.Contains(lower, "rinuncia"), strings.Contains(lower, "withdraw"):
+		return StatusWithdrawn
+	case prefix == "attesa", strings.Contains(prefix, "waiting"):
+		return StatusWaiting
+	case strings.Contains(lower, "non consentita"), strings.Contains(lower, "not possible"), strings.Contains(lower, "non ammess"), lower == "no":
+		return StatusNotAllowed
+	case prefix == "prenotato", prefix == "booked":
+		return StatusBooked
+	case prefix == "assegnato", prefix == "assigned", strings.HasPrefix(lower, "ammess"), lower == "si", lower == "sì", lower == "yes":
+		return StatusAssigned
+	case isCourseName(raw, courseTitles):
+		return StatusAssigned
+	default:
+		return StatusUnknown
+	}
+}
+
+// isCourseName reports if s is "<title>" or "<title> (<location>)" of one of the course titles,
+// any other text (e.g. a status we don't know yet) is not a course name
+func isCourseName(s string, courseTitles []string) bool {
+	title, _ := getCourseTitleLocation(strings.TrimSpace(s))
+	return title != "" && slices.ContainsFunc(courseTitles, func(t string) bool { return strings.EqualFold(t, title) })
+}
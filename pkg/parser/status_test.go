@@ -0,0 +1,33 @@
+package parser
+
+import "testing"
+
+func TestParseEnrolmentStatus(t *testing.T) {
+	courseTitles := []string{"INGEGNERIA INFORMATICA", "INGEGNERIA MECCANICA"}
+
+	tests := []struct {
+		raw  string
+		want EnrolmentStatus
+	}{
+		{raw: "Assegnato - INGEGNERIA INFORMATICA (MILANO LEONARDO)", want: StatusAssigned},
+		{raw: "Prenotato - INGEGNERIA MECCANICA", want: StatusBooked},
+		{raw: "Attesa - immatricolazione non consentita", want: StatusWaiting},
+		{raw: "Immatricolazione non consentita", want: StatusNotAllowed},
+		{raw: "NON AMMESSO", want: StatusNotAllowed},
+		{raw: "RINUNCIA", want: StatusWithdrawn},
+		{raw: "Si", want: StatusAssigned},
+		{raw: "INGEGNERIA INFORMATICA (Milano Leonardo)", want: StatusAssigned},
+		{raw: "ingegneria meccanica", want: StatusAssigned},
+		{raw: "ESCLUSO", want: StatusUnknown},
+		{raw: "INGEGNERIA AEROSPAZIALE (MILANO BOVISA)", want: StatusUnknown},
+		{raw: "", want: StatusUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			if got := ParseEnrolmentStatus(tt.raw, courseTitles); got != tt.want {
+				t.Errorf("ParseEnrolmentStatus(%q) = %q, want %q", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
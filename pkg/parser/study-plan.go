@@ -0,0 +1,272 @@
+package parser
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/constants"
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/scraper"
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/utils"
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/writer"
+	"github.com/PuerkitoBio/goquery"
+)
+
+type studyPlanColumn int
+
+const (
+	colCode studyPlanColumn = iota
+	colName
+	colSemester
+	colCfu
+	colSsd
+	colLanguage
+)
+
+var (
+	// "1° anno", "anno di corso 1", "1st year"
+	studyPlanYearRegex = regexp.MustCompile(`(?i)^(?:(\d)\s*°\s*anno|anno(?: di corso)?\s*(\d)|(\d)\s*(?:st|nd|rd|th)\s+year|year\s*(\d))\b`)
+	semesterRegex      = regexp.MustCompile(`\d`)
+)
+
+type StudyPlanUnit struct {
+	Code     string  `json:"code"`
+	Name     string  `json:"name"`
+	Semester uint8   `json:"semester"` // 1 or 2, 0 if annual or unknown
+	Cfu      float32 `json:"cfu"`
+	Ssd      string  `json:"ssd,omitempty"` // scientific-disciplinary sector, e.g. ING-INF/05
+	Language string  `json:"language,omitempty"`
+}
+
+type StudyPlanGroup struct {
+	Name      string          `json:"name"`
+	Mandatory bool            `json:"mandatory"` // false for elective groups ("a scelta")
+	Units     []StudyPlanUnit `json:"units"`
+}
+
+type StudyPlanYear struct {
+	Year   uint8            `json:"year"` // year of course, 0 if the page has no year headings
+	Groups []StudyPlanGroup `json:"groups"`
+}
+
+// StudyPlan is the content of a manifesto page
+type StudyPlan struct {
+	SchemaVersion uint   `json:"schemaVersion"`
+	Code          uint64 `json:"code"` // k_corso_la
+	AcademicYear  uint16 `json:"academicYear"`
+	Name          string `json:"name"`
+	School        string `json:"school"`
+	DegreeType    string `json:"degreeType"`
+	Url           string `json:"url"`
+
+	Years []StudyPlanYear `json:"years"`
+	// sum of the CFU of the mandatory units
+	MandatoryCfu float32 `json:"mandatoryCfu"`
+}
+
+// ParseStudyPlan parses the study plan table of a saved manifesto page.
+// The table columns are detected from its header, so the order of the columns does not matter.
+func ParseStudyPlan(data []byte, m scraper.Manifesto) (*StudyPlan, error) {
+	doc, err := utils.LoadLocalHtml(data)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &StudyPlan{
+		SchemaVersion: constants.OutputSchemaVersion,
+		Code:          m.CourseCode(),
+		AcademicYear:  m.Year,
+		Name:          m.Name,
+		School:        m.School,
+		DegreeType:    m.DegreeType,
+		Url:           m.Url,
+		Years:         []StudyPlanYear{},
+	}
+
+	var columns map[studyPlanColumn]int
+	var year *StudyPlanYear
+	var group *StudyPlanGroup
+	units := 0
+
+	doc.Find("tr").Each(func(i int, row *goquery.Selection) {
+		// layout rows wrapping the study plan table
+		if row.Find("table").Length() > 0 {
+			return
+		}
+
+		cells := row.ChildrenFiltered("td, th")
+		text := strings.Join(strings.Fields(row.Text()), " ")
+		lower := strings.ToLower(text)
+
+		if cols, ok := studyPlanColumns(cells); ok {
+			columns = cols
+			return
+		}
+
+		if cells.Length() <= 2 {
+			if match := studyPlanYearRegex.FindStringSubmatch(lower); match != nil {
+				n, _ := strconv.ParseUint(match[1]+match[2]+match[3]+match[4], 10, 8)
+				plan.Years = append(plan.Years, StudyPlanYear{Year: uint8(n), Groups: []StudyPlanGroup{}})
+				year, group = &plan.Years[len(plan.Years)-1], nil
+				return
+			}
+
+			if text != "" {
+				year = ensureStudyPlanYear(plan, year)
+				year.Groups = append(year.Groups, StudyPlanGroup{Name: text, Mandatory: !isElectiveGroup(lower), Units: []StudyPlanUnit{}})
+				group = &year.Groups[len(year.Groups)-1]
+			}
+			return
+		}
+
+		if columns == nil {
+			return
+		}
+
+		unit, ok := studyPlanUnit(cells, columns)
+		if !ok {
+			return
+		}
+
+		year = ensureStudyPlanYear(plan, year)
+		if group == nil {
+			year.Groups = append(year.Groups, StudyPlanGroup{Name: "", Mandatory: true, Units: []StudyPlanUnit{}})
+			group = &year.Groups[len(year.Groups)-1]
+		}
+		group.Units = append(group.Units, unit)
+		units++
+		if group.Mandatory {
+			plan.MandatoryCfu += unit.Cfu
+		}
+	})
+
+	if units == 0 {
+		return nil, fmt.Errorf("no course units found in the study plan of %s", m.Url)
+	}
+
+	// titles of other tables (notes, legend, ...) are detected as groups without units
+	years := make([]StudyPlanYear, 0, len(plan.Years))
+	for _, y := range plan.Years {
+		y.Groups = slices.DeleteFunc(y.Groups, func(g StudyPlanGroup) bool { return len(g.Units) == 0 })
+		if len(y.Groups) > 0 {
+			years = append(years, y)
+		}
+	}
+	plan.Years = years
+
+	return plan, nil
+}
+
+// ensureStudyPlanYear returns the current year, creating a year 0 for pages without year headings
+func ensureStudyPlanYear(plan *StudyPlan, year *StudyPlanYear) *StudyPlanYear {
+	if year != nil {
+		return year
+	}
+
+	plan.Years = append(plan.Years, StudyPlanYear{Year: 0, Groups: []StudyPlanGroup{}})
+	return &plan.Years[len(plan.Years)-1]
+}
+
+func isElectiveGroup(lower string) bool {
+	for _, s := range []string{"a scelta", "scelta", "elective", "opzional", "optional"} {
+		if strings.Contains(lower, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// studyPlanColumns detects the header row of the study plan table
+func studyPlanColumns(cells *goquery.Selection) (map[studyPlanColumn]int, bool) {
+	cols := map[studyPlanColumn]int{}
+	cells.Each(func(i int, cell *goquery.Selection) {
+		label := strings.ToLower(strings.TrimSpace(cell.Text()))
+		switch {
+		case label == "cfu" || strings.Contains(label, "credit"):
+			cols[colCfu] = i
+		case strings.Contains(label, "insegnamento") || label == "course" || strings.Contains(label, "course title"):
+			cols[colName] = i
+		case strings.HasPrefix(label, "cod") || label == "code":
+			cols[colCode] = i
+		case strings.HasPrefix(label, "sem"):
+			cols[colSemester] = i
+		case label == "ssd" || strings.Contains(label, "settore"):
+			cols[colSsd] = i
+		case strings.HasPrefix(label, "lingua") || label == "language":
+			cols[colLanguage] = i
+		}
+	})
+
+	_, hasName := cols[colName]
+	_, hasCfu := cols[colCfu]
+	return cols, hasName && hasCfu
+}
+
+func studyPlanUnit(cells *goquery.Selection, columns map[studyPlanColumn]int) (StudyPlanUnit, bool) {
+	get := func(c studyPlanColumn) string {
+		idx, ok := columns[c]
+		if !ok || idx >= cells.Length() {
+			return ""
+		}
+		return strings.Join(strings.Fields(cells.Eq(idx).Text()), " ")
+	}
+
+	unit := StudyPlanUnit{
+		Code:     get(colCode),
+		Name:     get(colName),
+		Ssd:      get(colSsd),
+		Language: get(colLanguage),
+	}
+	if unit.Name == "" {
+		return unit, false
+	}
+
+	// italian decimal separator
+	cfu, err := strconv.ParseFloat(strings.ReplaceAll(get(colCfu), ",", "."), 32)
+	if err != nil {
+		return unit, false
+	}
+	unit.Cfu = float32(cfu)
+
+	if sem, err := strconv.ParseUint(semesterRegex.FindString(get(colSemester)), 10, 8); err == nil && (sem == 1 || sem == 2) {
+		unit.Semester = uint8(sem)
+	}
+
+	return unit, true
+}
+
+// WriteStudyPlans parses the saved page of every manifesto and writes the study plans in absOutDir.
+// Manifesti without a saved page are skipped. Returns the number of written study plans.
+func WriteStudyPlans(absHtmlDir, absOutDir string, mans []scraper.Manifesto, indent bool) (int, error) {
+	w := writer.NewWriter[*StudyPlan](absOutDir)
+	written := map[string]bool{}
+	for _, m := range mans {
+		fn := m.StudyPlanFilename(".json")
+		if written[fn] {
+			continue // same course in another location
+		}
+
+		data, err := os.ReadFile(path.Join(absHtmlDir, m.StudyPlanFilename(".html")))
+		if err != nil {
+			continue
+		}
+
+		plan, err := ParseStudyPlan(data, m)
+		if err != nil {
+			slog.Warn("[manifesti] could not parse study plan", "filename", m.StudyPlanFilename(".html"), "error", err)
+			continue
+		}
+
+		if err := w.JsonWrite(fn, plan, indent); err != nil {
+			return len(written), fmt.Errorf("error while writing study plan %s: %w", fn, err)
+		}
+		written[fn] = true
+	}
+
+	return len(written), nil
+}
@@ -0,0 +1,70 @@
+package parser
+
+import (
+	"os"
+	"testing"
+
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/scraper"
+)
+
+func TestParseStudyPlan(t *testing.T) {
+	data, err := os.ReadFile("testdata/manifesto_358_2024.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := scraper.Manifesto{Name: "Ingegneria Informatica", Url: "https://example.com/ManifestoPublic.do?k_corso_la=358", Code: 358, Year: 2024}
+	plan, err := ParseStudyPlan(data, m)
+	if err != nil {
+		t.Fatalf("ParseStudyPlan() error = %v", err)
+	}
+
+	if plan.Code != 358 || plan.AcademicYear != 2024 {
+		t.Errorf("got code %d academic year %d, want 358 2024", plan.Code, plan.AcademicYear)
+	}
+	if plan.MandatoryCfu != 65 {
+		t.Errorf("got mandatory cfu %v, want 65", plan.MandatoryCfu)
+	}
+
+	type group struct {
+		year      uint8
+		name      string
+		mandatory bool
+		units     int
+	}
+	want := []group{
+		{year: 1, name: "", mandatory: true, units: 4},
+		{year: 2, name: "", mandatory: true, units: 2},
+		{year: 3, name: "", mandatory: true, units: 1},
+		{year: 3, name: "Insegnamenti a scelta", mandatory: false, units: 2},
+	}
+	got := []group{}
+	for _, y := range plan.Years {
+		for _, g := range y.Groups {
+			got = append(got, group{year: y.Year, name: g.Name, mandatory: g.Mandatory, units: len(g.Units)})
+		}
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got groups %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("group %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	first := plan.Years[0].Groups[0].Units
+	wantFirst := StudyPlanUnit{Code: "081372", Name: "ANALISI MATEMATICA 1", Semester: 1, Cfu: 10, Ssd: "MAT/05", Language: "IT"}
+	if first[0] != wantFirst {
+		t.Errorf("first unit = %+v, want %+v", first[0], wantFirst)
+	}
+	if first[3].Name != "FISICA" || first[3].Semester != 0 || first[3].Cfu != 12 {
+		t.Errorf("annual unit = %+v, want FISICA with semester 0 and 12 cfu", first[3])
+	}
+}
+
+func TestParseStudyPlanNoUnits(t *testing.T) {
+	if _, err := ParseStudyPlan([]byte(`<html><body><table><tr><td>Legenda</td></tr></table></body></html>`), scraper.Manifesto{}); err == nil {
+		t.Error("ParseStudyPlan() of a page without units should fail")
+	}
+}
@@ -0,0 +1,102 @@
+package parser
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+)
+
+type WarningKind string
+
+const (
+	// a course table row whose matricola hash is not in the merit table
+	WarningOrphanCourseRow WarningKind = "orphan_course_row"
+	// a merit row allowed to enroll, without any course
+	WarningEnrollWithoutCourse WarningKind = "enroll_without_course"
+	// the same matricola hash appears more than once in the merit table
+	WarningDuplicateId WarningKind = "duplicate_id"
+	// positions are not strictly increasing (duplicated) or do not start from 1
+	WarningNonMonotonicPosition WarningKind = "non_monotonic_position"
+	// a student has a better position but a lower result than the following one
+	WarningResultInversion WarningKind = "result_position_inversion"
+	// a course table row matches more than one merit row (rankings without matricola, see positional-match.go)
+	WarningAmbiguousMatch WarningKind = "ambiguous_positional_match"
+)
+
+type RankingWarning struct {
+	Kind      WarningKind `json:"kind"`
+	StudentId string      `json:"studentId,omitempty"`
+	// merit table position, or course table position if Course is set
+	Position uint16 `json:"position,omitempty"`
+	Course   string `json:"course,omitempty"`
+	Message  string `json:"message"`
+}
+
+// addWarning is not thread safe, callers running in goroutines must hold RankingParser.mu
+func (r *Ranking) addWarning(w RankingWarning) {
+	r.Warnings = append(r.Warnings, w)
+}
+
+func (r *Ranking) WarningCounts() map[WarningKind]int {
+	out := map[WarningKind]int{}
+	for _, w := range r.Warnings {
+		out[w.Kind]++
+	}
+	return out
+}
+
+// validate cross-checks merit and course data, run it after ensureSorting.
+// Orphan course rows and duplicate ids are detected while parsing the tables.
+func (r *Ranking) validate() {
+	for _, row := range r.Rows {
+		if row.CanEnroll && len(row.Courses) == 0 {
+			r.addWarning(RankingWarning{
+				Kind:      WarningEnrollWithoutCourse,
+				StudentId: row.Id,
+				Position:  row.Position,
+				Message:   "student can enroll but has no course",
+			})
+		}
+	}
+
+	if len(r.Rows) > 0 && r.Rows[0].Position != 1 {
+		r.addWarning(RankingWarning{
+			Kind:     WarningNonMonotonicPosition,
+			Position: r.Rows[0].Position,
+			Message:  fmt.Sprintf("first position is %d instead of 1", r.Rows[0].Position),
+		})
+	}
+
+	// rows are sorted by position
+	for i := 1; i < len(r.Rows); i++ {
+		prev, curr := r.Rows[i-1], r.Rows[i]
+		if prev.Position == curr.Position {
+			r.addWarning(RankingWarning{
+				Kind:      WarningNonMonotonicPosition,
+				StudentId: curr.Id,
+				Position:  curr.Position,
+				Message:   fmt.Sprintf("position %d is duplicated", curr.Position),
+			})
+		}
+
+		if prev.Result < curr.Result {
+			r.addWarning(RankingWarning{
+				Kind:      WarningResultInversion,
+				StudentId: curr.Id,
+				Position:  curr.Position,
+				Message:   fmt.Sprintf("result %.2f at position %d is greater than result %.2f at position %d", curr.Result, curr.Position, prev.Result, prev.Position),
+			})
+		}
+	}
+
+	// warnings are added by concurrent goroutines, sort them on every field to keep the output stable
+	slices.SortFunc(r.Warnings, func(a, b RankingWarning) int {
+		return cmp.Or(
+			cmp.Compare(a.Position, b.Position),
+			cmp.Compare(a.Kind, b.Kind),
+			cmp.Compare(a.Course, b.Course),
+			cmp.Compare(a.StudentId, b.StudentId),
+			cmp.Compare(a.Message, b.Message),
+		)
+	})
+}
@@ -0,0 +1,49 @@
+package privacy
+
+import (
+	"fmt"
+	"strings"
+)
+
+// BirthDatePolicy tells how much of the students' birth date is published.
+// Birth date, position and result together are quasi-identifying, so outputs
+// can generalise or drop it.
+type BirthDatePolicy string
+
+const (
+	BirthDateKeep      BirthDatePolicy = "keep"       // full date (YYYY-MM-DD)
+	BirthDateYearOnly  BirthDatePolicy = "year-only"  // only the year of birth
+	BirthDateAgeBucket BirthDatePolicy = "age-bucket" // age range in the ranking year, see AgeBucket
+	BirthDateDrop      BirthDatePolicy = "drop"       // nothing
+)
+
+var BirthDatePolicies = []BirthDatePolicy{BirthDateKeep, BirthDateYearOnly, BirthDateAgeBucket, BirthDateDrop}
+
+func ParseBirthDatePolicy(s string) (BirthDatePolicy, error) {
+	for _, p := range BirthDatePolicies {
+		if string(p) == strings.ToLower(strings.TrimSpace(s)) {
+			return p, nil
+		}
+	}
+
+	return "", fmt.Errorf("invalid birth date policy '%s', valid values: %v", s, BirthDatePolicies)
+}
+
+// AgeBucket returns the age range of a student born in birthYear, computed
+// in refYear (the ranking year). Most students are 18 or 19, so the other
+// ages are grouped in wider ranges.
+func AgeBucket(birthYear, refYear uint16) string {
+	age := int(refYear) - int(birthYear)
+	switch {
+	case age <= 17:
+		return "<=17"
+	case age <= 19:
+		return fmt.Sprint(age)
+	case age <= 21:
+		return "20-21"
+	case age <= 25:
+		return "22-25"
+	default:
+		return ">=26"
+	}
+}
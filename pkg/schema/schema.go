@@ -0,0 +1,161 @@
+package schema
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/utils"
+)
+
+const draft = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema is the subset of JSON Schema (draft 2020-12) we need to describe our output files.
+// It is generated from the Go types with Generate, so the Go types are the source of truth.
+type Schema struct {
+	Draft       string `json:"$schema,omitempty"`
+	Id          string `json:"$id,omitempty"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+
+	// string or []string
+	Type   any    `json:"type,omitempty"`
+	Format string `json:"format,omitempty"`
+	Const  any    `json:"const,omitempty"`
+	Enum   []any  `json:"enum,omitempty"`
+
+	Minimum *float64 `json:"minimum,omitempty"`
+	Maximum *float64 `json:"maximum,omitempty"`
+
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties any                `json:"additionalProperties,omitempty"` // bool or *Schema
+	PropertyNames        *Schema            `json:"propertyNames,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+
+	Items *Schema `json:"items,omitempty"`
+}
+
+var (
+	timeType = reflect.TypeOf(time.Time{})
+	dateType = reflect.TypeOf(utils.Date{})
+)
+
+// Generate builds the schema of v's type, following encoding/json rules
+// (json tags, omitempty, map keys as strings, nil slices and maps as null)
+func Generate(v any, id, title string) *Schema {
+	s := generate(reflect.TypeOf(v))
+	s.Draft = draft
+	s.Id = id
+	s.Title = title
+	return s
+}
+
+func generate(t reflect.Type) *Schema {
+	if t == timeType {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+
+	if t == dateType {
+		return &Schema{Type: "string", Format: "date"}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		s := generate(t.Elem())
+		s.Type = nullable(s.Type)
+		return s
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &Schema{Type: "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		s := &Schema{Type: "integer", Minimum: ptr(0)}
+		if t.Bits() <= 32 {
+			s.Maximum = ptr(float64(uint64(math.MaxUint64) >> (64 - t.Bits())))
+		}
+		return s
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		s := &Schema{Type: "array", Items: generate(t.Elem())}
+		if t.Kind() == reflect.Slice {
+			s.Type = nullable(s.Type)
+		}
+		return s
+	case reflect.Map:
+		s := &Schema{Type: nullable("object"), AdditionalProperties: generate(t.Elem())}
+		switch t.Key().Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			s.PropertyNames = &Schema{Pattern: "^-?[0-9]+$"}
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			s.PropertyNames = &Schema{Pattern: "^[0-9]+$"}
+		}
+		return s
+	case reflect.Struct:
+		return generateStruct(t)
+	default:
+		// interfaces and everything else accept any value
+		return &Schema{}
+	}
+}
+
+func generateStruct(t reflect.Type) *Schema {
+	s := &Schema{
+		Type:                 "object",
+		Properties:           map[string]*Schema{},
+		Required:             []string{},
+		AdditionalProperties: false,
+	}
+
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" && opts == "" {
+			continue
+		}
+
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			embedded := generateStruct(f.Type)
+			for k, v := range embedded.Properties {
+				s.Properties[k] = v
+			}
+			s.Required = append(s.Required, embedded.Required...)
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+
+		s.Properties[name] = generate(f.Type)
+		if !strings.Contains(opts, "omitempty") {
+			s.Required = append(s.Required, name)
+		}
+	}
+
+	return s
+}
+
+func nullable(t any) any {
+	if str, ok := t.(string); ok {
+		return []string{str, "null"}
+	}
+	return t
+}
+
+func ptr(f float64) *float64 {
+	return &f
+}
+
+func (s *Schema) Json() ([]byte, error) {
+	return json.MarshalIndent(s, "", "	")
+}
@@ -0,0 +1,102 @@
+package schema
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// Entry links the files matching Pattern (path.Match syntax, relative to the tree root)
+// to their schema. A nil Schema marks the files as known but not validated.
+type Entry struct {
+	Name    string
+	Pattern string
+	Schema  *Schema
+}
+
+type FileReport struct {
+	Path   string            `json:"path"`
+	Schema string            `json:"schema"`
+	Errors []ValidationError `json:"errors,omitempty"`
+	Err    string            `json:"error,omitempty"`
+}
+
+type TreeReport struct {
+	Files     int          `json:"files"`
+	Valid     int          `json:"valid"`
+	Invalid   []FileReport `json:"invalid"`
+	Unmatched []string     `json:"unmatched"`
+}
+
+func (r *TreeReport) Ok() bool {
+	return len(r.Invalid) == 0
+}
+
+// ValidateTree validates every json file inside root against the first matching entry
+func ValidateTree(root string, entries []Entry) (TreeReport, error) {
+	report := TreeReport{Invalid: []FileReport{}, Unmatched: []string{}}
+
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() || filepath.Ext(p) != ".json" {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		entry := matchEntry(rel, entries)
+		if entry == nil {
+			report.Unmatched = append(report.Unmatched, rel)
+			return nil
+		}
+
+		if entry.Schema == nil {
+			return nil
+		}
+
+		report.Files++
+		fr := FileReport{Path: rel, Schema: entry.Name}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			fr.Err = err.Error()
+			report.Invalid = append(report.Invalid, fr)
+			return nil
+		}
+
+		errs, err := entry.Schema.ValidateJson(data)
+		if err != nil {
+			fr.Err = err.Error()
+			report.Invalid = append(report.Invalid, fr)
+			return nil
+		}
+
+		if len(errs) > 0 {
+			fr.Errors = errs
+			report.Invalid = append(report.Invalid, fr)
+			return nil
+		}
+
+		report.Valid++
+		return nil
+	})
+
+	return report, err
+}
+
+func matchEntry(rel string, entries []Entry) *Entry {
+	for i, e := range entries {
+		if ok, _ := path.Match(e.Pattern, rel); ok {
+			return &entries[i]
+		}
+	}
+
+	return nil
+}
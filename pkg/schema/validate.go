@@ -0,0 +1,163 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/utils"
+)
+
+type ValidationError struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+func (e ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Path, e.Message)
+}
+
+// ValidateJson decodes data and validates it against the schema.
+// The returned errors are sorted by path.
+func (s *Schema) ValidateJson(data []byte) ([]ValidationError, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	errs := s.Validate(v)
+	sort.SliceStable(errs, func(i, j int) bool { return errs[i].Path < errs[j].Path })
+	return errs, nil
+}
+
+// Validate checks a value decoded with json.Decoder.UseNumber against the schema
+func (s *Schema) Validate(v any) []ValidationError {
+	return s.validate(v, "$")
+}
+
+func (s *Schema) validate(v any, path string) []ValidationError {
+	errs := make([]ValidationError, 0)
+	fail := func(format string, args ...any) []ValidationError {
+		return append(errs, ValidationError{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if s.Type != nil && !slices.Contains(typeNames(s.Type), jsonType(v)) {
+		// integers are numbers too
+		if !(jsonType(v) == "integer" && slices.Contains(typeNames(s.Type), "number")) {
+			return fail("expected type %v, got %s", s.Type, jsonType(v))
+		}
+	}
+
+	if s.Const != nil && fmt.Sprint(s.Const) != fmt.Sprint(v) {
+		return fail("expected constant value %v, got %v", s.Const, v)
+	}
+
+	if len(s.Enum) > 0 && !slices.ContainsFunc(s.Enum, func(e any) bool { return fmt.Sprint(e) == fmt.Sprint(v) }) {
+		return fail("value %v is not one of %v", v, s.Enum)
+	}
+
+	switch value := v.(type) {
+	case json.Number:
+		f, _ := value.Float64()
+		if s.Minimum != nil && f < *s.Minimum {
+			errs = fail("value %v is less than minimum %v", f, *s.Minimum)
+		}
+		if s.Maximum != nil && f > *s.Maximum {
+			errs = fail("value %v is greater than maximum %v", f, *s.Maximum)
+		}
+
+	case string:
+		if s.Pattern != "" {
+			if ok, _ := regexp.MatchString(s.Pattern, value); !ok {
+				errs = fail("value '%s' does not match pattern %s", value, s.Pattern)
+			}
+		}
+		if s.Format == "date-time" {
+			if _, err := time.Parse(time.RFC3339, value); err != nil {
+				errs = fail("value '%s' is not a valid date-time", value)
+			}
+		}
+		if s.Format == "date" {
+			if _, err := time.Parse(utils.DateLayout, value); err != nil {
+				errs = fail("value '%s' is not a valid date", value)
+			}
+		}
+
+	case []any:
+		if s.Items != nil {
+			for i, item := range value {
+				errs = append(errs, s.Items.validate(item, fmt.Sprintf("%s[%d]", path, i))...)
+			}
+		}
+
+	case map[string]any:
+		for _, req := range s.Required {
+			if _, ok := value[req]; !ok {
+				errs = fail("missing required property '%s'", req)
+			}
+		}
+
+		for k, item := range value {
+			childPath := path + "." + k
+			if s.PropertyNames != nil {
+				errs = append(errs, s.PropertyNames.validate(k, childPath)...)
+			}
+
+			if prop, ok := s.Properties[k]; ok {
+				errs = append(errs, prop.validate(item, childPath)...)
+				continue
+			}
+
+			switch additional := s.AdditionalProperties.(type) {
+			case bool:
+				if !additional {
+					errs = append(errs, ValidationError{Path: childPath, Message: "additional property not allowed"})
+				}
+			case *Schema:
+				errs = append(errs, additional.validate(item, childPath)...)
+			}
+		}
+	}
+
+	return errs
+}
+
+func typeNames(t any) []string {
+	switch tt := t.(type) {
+	case string:
+		return []string{tt}
+	case []string:
+		return tt
+	default:
+		return nil
+	}
+}
+
+func jsonType(v any) string {
+	switch value := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case json.Number:
+		if _, err := value.Int64(); err == nil {
+			return "integer"
+		}
+		return "number"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		return "unknown"
+	}
+}
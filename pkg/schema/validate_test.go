@@ -0,0 +1,204 @@
+package schema
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/utils"
+)
+
+type testRow struct {
+	Id       string         `json:"id"`
+	Position uint16         `json:"position"`
+	Score    float64        `json:"score"`
+	Ofa      bool           `json:"ofa"`
+	Note     *string        `json:"note"`
+	Birth    utils.Date     `json:"birth"`
+	Tags     []string       `json:"tags,omitempty"`
+	Seats    map[uint]int   `json:"seats,omitempty"`
+	Extra    map[string]any `json:"extra,omitempty"`
+}
+
+type testFile struct {
+	SchemaVersion uint      `json:"schemaVersion"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+	Rows          []testRow `json:"rows"`
+}
+
+const validRow = `{"id":"a","position":1,"score":10.5,"ofa":false,"note":null,"birth":"2005-03-01"}`
+
+func testSchema() *Schema {
+	s := Generate(testFile{}, "test", "test")
+	s.Properties["schemaVersion"].Const = 3
+	return s
+}
+
+func TestValidateJson(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []ValidationError
+	}{
+		{
+			name: "valid",
+			data: `{"schemaVersion":3,"updatedAt":"2024-07-01T10:00:00Z","rows":[` + validRow + `]}`,
+		},
+		{
+			name: "null slice",
+			data: `{"schemaVersion":3,"updatedAt":"2024-07-01T10:00:00Z","rows":null}`,
+		},
+		{
+			name: "missing required property",
+			data: `{"schemaVersion":3,"rows":[]}`,
+			want: []ValidationError{{Path: "$", Message: "missing required property 'updatedAt'"}},
+		},
+		{
+			name: "optional properties can be omitted",
+			data: `{"schemaVersion":3,"updatedAt":"2024-07-01T10:00:00Z","rows":[{"id":"a","position":1,"score":1,"ofa":true,"note":"x","birth":"2005-03-01","tags":["t"]}]}`,
+		},
+		{
+			name: "wrong types",
+			data: `{"schemaVersion":3,"updatedAt":"2024-07-01T10:00:00Z","rows":[{"id":1,"position":1.5,"score":"10","ofa":"no","note":null,"birth":"2005-03-01"}]}`,
+			want: []ValidationError{
+				{Path: "$.rows[0].id", Message: "expected type string, got integer"},
+				{Path: "$.rows[0].ofa", Message: "expected type boolean, got string"},
+				{Path: "$.rows[0].position", Message: "expected type integer, got number"},
+				{Path: "$.rows[0].score", Message: "expected type number, got string"},
+			},
+		},
+		{
+			name: "integer is a number",
+			data: `{"schemaVersion":3,"updatedAt":"2024-07-01T10:00:00Z","rows":[{"id":"a","position":1,"score":10,"ofa":false,"note":null,"birth":"2005-03-01"}]}`,
+		},
+		{
+			name: "unsigned range",
+			data: `{"schemaVersion":3,"updatedAt":"2024-07-01T10:00:00Z","rows":[{"id":"a","position":70000,"score":1,"ofa":false,"note":null,"birth":"2005-03-01"}]}`,
+			want: []ValidationError{{Path: "$.rows[0].position", Message: "value 70000 is greater than maximum 65535"}},
+		},
+		{
+			name: "additional property not allowed",
+			data: `{"schemaVersion":3,"updatedAt":"2024-07-01T10:00:00Z","rows":[],"unknown":1}`,
+			want: []ValidationError{{Path: "$.unknown", Message: "additional property not allowed"}},
+		},
+		{
+			name: "additional properties of maps are validated",
+			data: `{"schemaVersion":3,"updatedAt":"2024-07-01T10:00:00Z","rows":[{"id":"a","position":1,"score":1,"ofa":false,"note":null,"birth":"2005-03-01","seats":{"1":2,"2":"x"},"extra":{"k":[1]}}]}`,
+			want: []ValidationError{{Path: "$.rows[0].seats.2", Message: "expected type integer, got string"}},
+		},
+		{
+			name: "map key pattern",
+			data: `{"schemaVersion":3,"updatedAt":"2024-07-01T10:00:00Z","rows":[{"id":"a","position":1,"score":1,"ofa":false,"note":null,"birth":"2005-03-01","seats":{"-1":2}}]}`,
+			want: []ValidationError{{Path: "$.rows[0].seats.-1", Message: "value '-1' does not match pattern ^[0-9]+$"}},
+		},
+		{
+			name: "formats",
+			data: `{"schemaVersion":3,"updatedAt":"yesterday","rows":[{"id":"a","position":1,"score":1,"ofa":false,"note":null,"birth":"01/03/2005"}]}`,
+			want: []ValidationError{
+				{Path: "$.rows[0].birth", Message: "value '01/03/2005' is not a valid date"},
+				{Path: "$.updatedAt", Message: "value 'yesterday' is not a valid date-time"},
+			},
+		},
+		{
+			name: "const",
+			data: `{"schemaVersion":2,"updatedAt":"2024-07-01T10:00:00Z","rows":[]}`,
+			want: []ValidationError{{Path: "$.schemaVersion", Message: "expected constant value 3, got 2"}},
+		},
+	}
+
+	s := testSchema()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.ValidateJson([]byte(tt.data))
+			if err != nil {
+				t.Fatalf("ValidateJson() error = %v", err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ValidateJson() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateJsonInvalid(t *testing.T) {
+	if _, err := testSchema().ValidateJson([]byte(`{"schemaVersion":`)); err == nil {
+		t.Error("ValidateJson() of truncated json should fail")
+	}
+}
+
+func TestMatchEntry(t *testing.T) {
+	entries := []Entry{
+		{Name: "all", Pattern: "manifesti/all.json"},
+		{Name: "degree-type", Pattern: "manifesti/*.json"},
+		{Name: "ranking", Pattern: "rankings/*.json"},
+	}
+
+	tests := []struct {
+		rel  string
+		want string
+	}{
+		{rel: "manifesti/all.json", want: "all"},
+		{rel: "manifesti/LT.json", want: "degree-type"},
+		{rel: "rankings/2024_20103_abcd.json", want: "ranking"},
+		{rel: "rankings/nested/x.json", want: ""},
+		{rel: "other.json", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rel, func(t *testing.T) {
+			got := ""
+			if e := matchEntry(tt.rel, entries); e != nil {
+				got = e.Name
+			}
+			if got != tt.want {
+				t.Errorf("matchEntry(%q) = %q, want %q", tt.rel, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateTree(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"rankings/valid.json":   `{"schemaVersion":3,"updatedAt":"2024-07-01T10:00:00Z","rows":[` + validRow + `]}`,
+		"rankings/invalid.json": `{"schemaVersion":3,"rows":[]}`,
+		"rankings/broken.json":  `{`,
+		"schemas/x.json":        `{}`,
+		"unknown/y.json":        `{}`,
+		"rankings/notes.txt":    `not json`,
+	}
+	for rel, content := range files {
+		p := filepath.Join(root, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(p), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	report, err := ValidateTree(root, []Entry{
+		{Name: "ranking", Pattern: "rankings/*.json", Schema: testSchema()},
+		{Name: "schema", Pattern: "schemas/*.json"},
+	})
+	if err != nil {
+		t.Fatalf("ValidateTree() error = %v", err)
+	}
+
+	if report.Files != 3 || report.Valid != 1 || report.Ok() {
+		t.Errorf("got files %d valid %d ok %v, want 3 1 false", report.Files, report.Valid, report.Ok())
+	}
+	invalid := []string{}
+	for _, fr := range report.Invalid {
+		invalid = append(invalid, fr.Path)
+	}
+	slices.Sort(invalid)
+	if !slices.Equal(invalid, []string{"rankings/broken.json", "rankings/invalid.json"}) {
+		t.Errorf("got invalid %v", invalid)
+	}
+	if !slices.Equal(report.Unmatched, []string{"unknown/y.json"}) {
+		t.Errorf("got unmatched %v, want [unknown/y.json]", report.Unmatched)
+	}
+}
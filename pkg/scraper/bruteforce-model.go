@@ -0,0 +1,188 @@
+package scraper
+
+import (
+	"cmp"
+	"log/slog"
+	"os"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/constants"
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/utils"
+	"github.com/PuerkitoBio/goquery"
+)
+
+// hex suffixes are grouped in ranges by their first hex char (16 ranges of 4096 suffixes)
+const (
+	hexRanges    = 16
+	hexRangeSize = hexCombos / hexRanges
+)
+
+// saved HTML folders, e.g. 2020_20002_html (old format, without hex) or 2024_20103_2d5d_html
+var savedHtmlFolderRegex = regexp.MustCompile(`^(\d{4})_2(\d{4})(?:_([0-9a-f]{4}))?_html$`)
+
+// PhaseIdStats is what we know about a phase ID from the saved HTML folders
+type PhaseIdStats struct {
+	PhaseID uint           `json:"phaseId"`
+	Count   int            `json:"count"`
+	Years   []uint         `json:"years"`
+	Schools map[string]int `json:"schools"` // school heading of the ranking -> count
+	Hexes   []string       `json:"hexes"`
+}
+
+func (s *PhaseIdStats) lastYear() uint {
+	if len(s.Years) == 0 {
+		return 0
+	}
+	return s.Years[len(s.Years)-1]
+}
+
+// BruteforceModel is the distribution of phase IDs and hex suffixes learned from the saved HTML folders
+type BruteforceModel struct {
+	Folders   int                      `json:"folders"`
+	Phases    []PhaseIdStats           `json:"phases"`    // most likely first
+	ByYear    map[uint][]uint          `json:"byYear"`    // year -> phase IDs
+	HexRanges [hexRanges]int           `json:"hexRanges"` // count of known hexes by first hex char
+	known     map[uint]map[uint]string // year -> phase ID -> hex
+}
+
+// AnalyzeSavedHtmls learns the bruteforce model from the folder names (and index.html) of the saved rankings
+func AnalyzeSavedHtmls(absSavedHtmlsDir string) *BruteforceModel {
+	model := &BruteforceModel{
+		Phases: []PhaseIdStats{},
+		ByYear: map[uint][]uint{},
+		known:  map[uint]map[uint]string{},
+	}
+
+	entries, err := utils.GetEntriesInFolder(absSavedHtmlsDir)
+	if err != nil {
+		slog.Error("[bruteforce] Could not read saved HTML entries from local folder", "folderAbsPath", absSavedHtmlsDir, "error", err)
+		return model
+	}
+
+	phases := map[uint]*PhaseIdStats{}
+	for _, entry := range entries {
+		name := entry.Name()
+		match := savedHtmlFolderRegex.FindStringSubmatch(name)
+		if !entry.IsDir() || match == nil {
+			slog.Warn("[bruteforce] Saved HTML folder name is not recognized", "name", name)
+			continue
+		}
+
+		year, _ := strconv.ParseUint(match[1], 10, 16)
+		phaseID, _ := strconv.ParseUint(match[2], 10, 16)
+		hex := match[3]
+
+		p, ok := phases[uint(phaseID)]
+		if !ok {
+			p = &PhaseIdStats{PhaseID: uint(phaseID), Years: []uint{}, Schools: map[string]int{}, Hexes: []string{}}
+			phases[uint(phaseID)] = p
+		}
+
+		model.Folders++
+		p.Count++
+		if !slices.Contains(p.Years, uint(year)) {
+			p.Years = append(p.Years, uint(year))
+		}
+		if !slices.Contains(model.ByYear[uint(year)], p.PhaseID) {
+			model.ByYear[uint(year)] = append(model.ByYear[uint(year)], p.PhaseID)
+		}
+		if school := readSavedHtmlSchool(path.Join(absSavedHtmlsDir, name)); school != "" {
+			p.Schools[school]++
+		}
+		if hex != "" {
+			p.Hexes = append(p.Hexes, hex)
+			first, _ := strconv.ParseUint(hex[:1], 16, 8)
+			model.HexRanges[first]++
+		}
+
+		if _, ok := model.known[uint(year)]; !ok {
+			model.known[uint(year)] = map[uint]string{}
+		}
+		model.known[uint(year)][p.PhaseID] = hex
+	}
+
+	for _, p := range phases {
+		slices.Sort(p.Years)
+		slices.Sort(p.Hexes)
+		model.Phases = append(model.Phases, *p)
+	}
+	for _, ids := range model.ByYear {
+		slices.Sort(ids)
+	}
+
+	// phase IDs seen recently and often are the most likely to be used again
+	slices.SortFunc(model.Phases, func(a, b PhaseIdStats) int {
+		return cmp.Or(
+			cmp.Compare(b.lastYear(), a.lastYear()),
+			cmp.Compare(b.Count, a.Count),
+			cmp.Compare(a.PhaseID, b.PhaseID),
+		)
+	})
+
+	return model
+}
+
+// readSavedHtmlSchool returns the school heading (italian) of a saved ranking, empty if unknown
+func readSavedHtmlSchool(absFolder string) string {
+	f, err := os.Open(path.Join(absFolder, constants.OutputHtmlRanking_IndexFilename))
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	doc, err := goquery.NewDocumentFromReader(f)
+	if err != nil {
+		return ""
+	}
+
+	// headings are "italian<br/>english", the third one is the school
+	heading := doc.Find(".CenterBar .intestazione").Eq(2)
+	if heading.Length() == 0 {
+		return ""
+	}
+	return strings.TrimSpace(heading.Contents().First().Text())
+}
+
+// PhaseIDs returns the known phase IDs, most likely first
+func (m *BruteforceModel) PhaseIDs() []uint {
+	ids := make([]uint, 0, len(m.Phases))
+	for _, p := range m.Phases {
+		ids = append(ids, p.PhaseID)
+	}
+	return ids
+}
+
+// KnownHex returns the hex of the saved ranking of a phase in a year
+func (m *BruteforceModel) KnownHex(year, phaseID uint) (string, bool) {
+	hex, ok := m.known[year][phaseID]
+	return hex, ok
+}
+
+// HexOrder returns every hex suffix, the ranges containing more known hexes first
+func (m *BruteforceModel) HexOrder() []int {
+	ranges := make([]int, 0, hexRanges)
+	for r := range hexRanges {
+		ranges = append(ranges, r)
+	}
+	slices.SortStableFunc(ranges, func(a, b int) int { return cmp.Compare(m.HexRanges[b], m.HexRanges[a]) })
+
+	order := make([]int, 0, hexCombos)
+	for _, r := range ranges {
+		for hex := r * hexRangeSize; hex < (r+1)*hexRangeSize; hex++ {
+			order = append(order, hex)
+		}
+	}
+	return order
+}
+
+func (m *BruteforceModel) Log() {
+	for _, p := range m.Phases {
+		slog.Info("[bruteforce-model] phase ID", "id", p.PhaseID, "count", p.Count, "years", p.Years, "schools", p.Schools)
+	}
+	slog.Info("[bruteforce-model] hex ranges (by first hex char)", "counts", m.HexRanges)
+	slog.Info("[bruteforce-model] summary", "folders", m.Folders, "phaseIDs", len(m.Phases), "years", len(m.ByYear))
+}
@@ -7,10 +7,8 @@ import (
 	"os"
 	"slices"
 	"strconv"
-	"strings"
-	"time"
 
-	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/constants"
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/config"
 	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/utils"
 	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/writer"
 )
@@ -24,63 +22,57 @@ const (
 // x is a number, h is an hex char, so all the combinations would be 47'775'744.
 // It is probable by inspection, that those are static IDs representing the ranking phase
 // so we can check only for those to save on the number of links to check.
-// We extract programmatically IDs from saved html folders name, which represent the phase IDs
-func extractPhaseIDs(savedHtmlsAbsPath string) []uint {
-	out := []uint{}
-	// get all saved HTML folders
-	entries, err := utils.GetEntriesInFolder(savedHtmlsAbsPath)
-	if err != nil {
-		slog.Error("[bruteforce] Could not read saved HTML entries from local folder", "folderAbsPath", savedHtmlsAbsPath)
-	}
-
-	for _, entry := range entries {
-		name := entry.Name() // e.g. 2020_20002_html; 2024_20103_2d5d_html
-		parts := strings.Split(name, "_")
-		if len(parts) < 3 {
-			slog.Warn("[bruteforce] Saved HTML folder name is not recognized", "name", name)
-			continue
-		}
-
-		rawID := parts[1] // e.g. 20002; 20103
-		parsedID, err := strconv.Atoi(strings.TrimPrefix(rawID, "2"))
-		if err != nil {
-			slog.Error("[bruteforce] Found HTML phase ID not integer", "rawID", parts[1], "folder_name", name)
-		}
+// We learn the phase IDs (and how likely they are) from saved html folders name, see AnalyzeSavedHtmls
 
-		uintID := uint(parsedID) // e.g. 2; 103
-		alreadyKnown := slices.Contains(out, uintID)
-
-		// slog.Debug("[bruteforce] extractPhaseIDs single", "ID", uintID, "isNew", !alreadyKnown)
-		if !alreadyKnown {
-			out = append(out, uintID)
-		}
-	}
-
-	slices.SortStableFunc(out, func(i, j uint) int {
-		return int(i) - int(j)
-	})
-
-	slog.Info("[bruteforce] final known phaseIDs", "data", out)
+// BruteforcePhaseStats is the result of the bruteforce of a phase ID
+type BruteforcePhaseStats struct {
+	PhaseID      uint     `json:"phaseId"`
+	Requests     int      `json:"requests"`
+	Known        bool     `json:"known"`        // already saved for the year, not requested
+	Done         bool     `json:"done"`         // known, early stopped or every hex range tested
+	TestedRanges []int    `json:"testedRanges"` // hex ranges (by first hex char) fully tested
+	Hits         []string `json:"hits"`
+}
 
-	return out
+// BruteforceStats reports how many requests the model ordering and the early stop saved
+type BruteforceStats struct {
+	Year       uint `json:"year"`
+	EarlyStop  bool `json:"earlyStop"`
+	Candidates int  `json:"candidates"` // links of an exhaustive bruteforce
+	Requests   int  `json:"requests"`
+	// candidates of the hex ranges not tested yet, because the bruteforce request budget stopped the bruteforce
+	Untested int `json:"untested"`
+	// candidates of the done phases which were not requested
+	Saved int `json:"saved"`
+	Hits  int `json:"hits"`
+	// the bruteforce request budget stopped the bruteforce, the next run resumes it
+	Incomplete bool                   `json:"incomplete"`
+	Phases     []BruteforcePhaseStats `json:"phases"`
 }
 
 type Bruteforcer struct {
 	Year       uint
 	validLinks []string
-	phaseIDs   []uint
+	model      *BruteforceModel
+	stats      BruteforceStats
+	domain     string
+	cfg        config.BruteforceConfig
+	budget     *utils.HttpBudget // not counted against the host budget of the ranking downloads
 
 	writer writer.Writer[[]string]
 }
 
-func NewBruteforcer(absOutDir, absSavedHtmlsDir string, year uint) *Bruteforcer {
+func NewBruteforcer(absOutDir, absSavedHtmlsDir string, year uint, domain string, cfg config.BruteforceConfig) *Bruteforcer {
 	writer := writer.NewWriter[[]string](absOutDir)
 
 	return &Bruteforcer{
 		validLinks: []string{},
 		Year:       year,
+		domain:     domain,
+		cfg:        cfg,
+		budget:     utils.NewHttpBudget(cfg.Budget),
 		writer:     writer,
-		phaseIDs:   extractPhaseIDs(absSavedHtmlsDir),
+		model:      AnalyzeSavedHtmls(absSavedHtmlsDir),
 	}
 }
 
@@ -88,6 +80,10 @@ func (bf *Bruteforcer) getFilename() string {
 	return fmt.Sprintf("valid_links_%d.json", bf.Year)
 }
 
+func (bf *Bruteforcer) getStatsFilename() string {
+	return fmt.Sprintf("stats_%d.json", bf.Year)
+}
+
 func (bf *Bruteforcer) write() {
 	err := bf.writer.JsonWrite(bf.getFilename(), bf.validLinks, true)
 	if err != nil {
@@ -99,6 +95,25 @@ func (bf *Bruteforcer) write() {
 	}
 
 	slog.Info("[bruteforce] successfully written to file", "count", len(bf.validLinks), "year", bf.Year)
+
+	statsWriter := writer.NewWriter[BruteforceStats](bf.writer.DirPath)
+	if err := statsWriter.JsonWrite(bf.getStatsFilename(), bf.stats, true); err != nil {
+		slog.Error("[bruteforce] error while writing stats to filesystem", "path", statsWriter.GetFilePath(bf.getStatsFilename()), "err", err)
+	}
+}
+
+// readIncompleteStats returns the stats of a previous bruteforce of the year stopped by the budget
+func (bf *Bruteforcer) readIncompleteStats() (BruteforceStats, bool) {
+	statsWriter := writer.NewWriter[BruteforceStats](bf.writer.DirPath)
+	stats, err := statsWriter.JsonRead(bf.getStatsFilename())
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			slog.Error("[bruteforce] error while reading saved stats file", "year", bf.Year, "path", statsWriter.GetFilePath(bf.getStatsFilename()), "err", err)
+		}
+		return stats, false
+	}
+
+	return stats, stats.Incomplete
 }
 
 func (bf *Bruteforcer) ReadSavedValidLinks() []string {
@@ -116,7 +131,7 @@ func (bf *Bruteforcer) ReadSavedValidLinks() []string {
 
 func (bf *Bruteforcer) generateLink(phaseID uint, randomHex int) string {
 	return fmt.Sprintf("https://%s/%d_2%04d_%04x_html/%d_2%04d_generale.html",
-		constants.WebPolimiRisultatiAmmissioneDomainName,
+		bf.domain,
 		bf.Year,
 		phaseID,
 		randomHex,
@@ -125,44 +140,128 @@ func (bf *Bruteforcer) generateLink(phaseID uint, randomHex int) string {
 	)
 }
 
+// Start tests the phase IDs, most likely first, and their hex suffixes, most likely ranges first.
+// With early stop, a phase ID is not tested anymore after the range containing a hit,
+// and phase IDs already saved for the year are not tested at all.
+// If the bruteforce request budget stops the bruteforce, the progress is saved and the next run resumes it.
 func (bf *Bruteforcer) Start() []string {
-	saved := bf.ReadSavedValidLinks()
-
-	if len(saved) > 0 {
-		slog.Info("[bruteforce] results for the specified year already exists, returning saved links", "year", bf.Year, "path", bf.writer.GetFilePath(bf.getFilename()))
-		return saved
+	prev, resume := bf.readIncompleteStats()
+	if !resume {
+		saved := bf.ReadSavedValidLinks()
+		if len(saved) > 0 {
+			slog.Info("[bruteforce] results for the specified year already exists, returning saved links", "year", bf.Year, "path", bf.writer.GetFilePath(bf.getFilename()))
+			return saved
+		}
 	}
 
-	// total links to test
-	combos := len(bf.phaseIDs) * hexCombos
-	links := make([]string, combos)
+	phaseIDs := bf.model.PhaseIDs()
+	hexOrder := bf.model.HexOrder()
+	bf.stats = BruteforceStats{
+		Year:       bf.Year,
+		EarlyStop:  bf.cfg.EarlyStop,
+		Candidates: len(phaseIDs) * hexCombos,
+		Phases:     []BruteforcePhaseStats{},
+	}
 
-	slog.Info("[bruteforce] started bruteforce, it might take a while", "year", bf.Year, "combos", combos)
+	previous := map[uint]BruteforcePhaseStats{}
+	if resume {
+		for _, p := range prev.Phases {
+			previous[p.PhaseID] = p
+		}
+		slog.Info("[bruteforce] resuming the bruteforce stopped by the bruteforce request budget", "year", bf.Year, "requests", prev.Requests, "untested", prev.Untested)
+	}
 
-	for i, id := range bf.phaseIDs {
-		offset := i * hexCombos
+	slog.Info("[bruteforce] started bruteforce, it might take a while", "year", bf.Year, "phaseIDs", phaseIDs, "maxCombos", bf.stats.Candidates, "earlyStop", bf.cfg.EarlyStop)
 
-		for randomHex := range hexCombos {
-			idx := offset + randomHex
-			links[idx] = bf.generateLink(id, randomHex)
+	budgetExceeded := false
+	for _, id := range phaseIDs {
+		phase, found := previous[id]
+		if !found {
+			phase = BruteforcePhaseStats{PhaseID: id, Hits: []string{}, TestedRanges: []int{}}
 		}
-	}
 
-	slog.Debug("[bruteforce] generated links to test", "count", combos, "first", links[0], "last", links[combos-1])
-	results := utils.HttpHeadAll(links, 200, 1000, 10*time.Second)
+		if !phase.Done && !budgetExceeded {
+			budgetExceeded = bf.bruteforcePhase(&phase, hexOrder)
+			slog.Info("[bruteforce] phase ID done", "id", id, "requests", phase.Requests, "hits", len(phase.Hits), "known", phase.Known, "done", phase.Done)
+			if budgetExceeded {
+				slog.Error("[bruteforce] bruteforce request budget exceeded, stopping the bruteforce. The next run resumes it", "phaseID", id)
+			}
+		}
 
-	for _, result := range results {
-		if result.StatusCode == 200 {
-			slog.Info("[HTTP_HEAD] link 200", "link", result.Link, "statusCode", result.StatusCode)
-			bf.validLinks = append(bf.validLinks, result.Link)
+		if phase.Done {
+			// candidates skipped thanks to the model ordering and the early stop
+			bf.stats.Saved += max(0, hexCombos-phase.Requests)
+		} else {
+			bf.stats.Untested += hexCombos - len(phase.TestedRanges)*hexRangeSize
+		}
+		if !found && !phase.Done && phase.Requests == 0 {
+			continue // not reached
 		}
+
+		bf.validLinks = append(bf.validLinks, phase.Hits...)
+		bf.stats.Requests += phase.Requests
+		bf.stats.Hits += len(phase.Hits)
+		bf.stats.Phases = append(bf.stats.Phases, phase)
 	}
 
-	slog.Info("[bruteforce] ended bruteforce", "year", bf.Year)
+	bf.stats.Incomplete = budgetExceeded
+	slog.Info("[bruteforce] ended bruteforce", "year", bf.Year, "requests", bf.stats.Requests, "saved", bf.stats.Saved, "untested", bf.stats.Untested, "hits", bf.stats.Hits, "incomplete", bf.stats.Incomplete)
 	bf.write()
 	return bf.validLinks
 }
 
+// bruteforcePhase tests the hex ranges of the phase not tested yet, returns true if the
+// bruteforce request budget is exceeded. The requests of the range stopped by the budget are
+// repeated by the next run, since the range is not marked as tested.
+func (bf *Bruteforcer) bruteforcePhase(phase *BruteforcePhaseStats, hexOrder []int) bool {
+	if hex, ok := bf.model.KnownHex(bf.Year, phase.PhaseID); ok && bf.cfg.EarlyStop {
+		slog.Debug("[bruteforce] phase ID already saved for the year, skipping", "id", phase.PhaseID, "hex", hex)
+		phase.Known, phase.Done = true, true
+		if hex != "" {
+			randomHex, _ := strconv.ParseUint(hex, 16, 16)
+			phase.Hits = append(phase.Hits, bf.generateLink(phase.PhaseID, int(randomHex)))
+		}
+		return false
+	}
+
+	for start := 0; start < len(hexOrder); start += hexRangeSize {
+		hexRange := hexOrder[start] / hexRangeSize
+		if slices.Contains(phase.TestedRanges, hexRange) {
+			continue
+		}
+
+		links := make([]string, 0, hexRangeSize)
+		for _, randomHex := range hexOrder[start : start+hexRangeSize] {
+			links = append(links, bf.generateLink(phase.PhaseID, randomHex))
+		}
+
+		budgetExceeded := false
+		for _, result := range utils.HttpHeadAll(links, bf.cfg.Workers, bf.cfg.Rps, bf.cfg.Timeout, bf.budget) {
+			if errors.Is(result.Err, utils.ErrHttpBudgetExceeded) {
+				budgetExceeded = true
+				continue
+			}
+			phase.Requests++
+			if result.StatusCode == 200 && !slices.Contains(phase.Hits, result.Link) {
+				slog.Info("[HTTP_HEAD] link 200", "link", result.Link, "statusCode", result.StatusCode)
+				phase.Hits = append(phase.Hits, result.Link)
+			}
+		}
+
+		if budgetExceeded {
+			return true
+		}
+		phase.TestedRanges = append(phase.TestedRanges, hexRange)
+
+		if len(phase.Hits) > 0 && bf.cfg.EarlyStop {
+			break
+		}
+	}
+
+	phase.Done = true
+	return false
+}
+
 // I leave here two knownPhaseIDs slices as backup
 // the second is programatically generated with the function `extractPhaseIDs` after 2025 rankings
 // [2 5 6 7 8 9 10 11 12 13 15 16 17 18 19 20 21 22 24 25 26 32 33 34 35 37 39 40 41 42 43 44 45 46 47 48 52 54 55 56 57 58 59 60 64 69 82 83 87 91 93 94 95 96 102 103 104]
@@ -0,0 +1,261 @@
+package scraper
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/constants"
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/utils"
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/writer"
+	"github.com/PuerkitoBio/goquery"
+)
+
+const (
+	LinkSourceAvvisi      = "avvisi"       // news of the avvisi page (italian)
+	LinkSourceNewsEn      = "news-en"      // news of the english news page
+	LinkSourceSchoolPages = "school-pages" // admission pages of the schools
+	LinkSourceDirectory   = "directory"    // directory listing of the rankings domain
+	LinkSourceManual      = "manual"       // links added by hand in links/manual.json
+)
+
+// LinkSources are all the available link sources, in the order they are run
+var LinkSources = []string{LinkSourceAvvisi, LinkSourceNewsEn, LinkSourceSchoolPages, LinkSourceDirectory, LinkSourceManual}
+
+// rankings folders in the directory listing, e.g. 2020_20002_html/ or 2024_20103_2d5d_html/
+var rankingFolderRegex = regexp.MustCompile(`^/?(\d{4}_\d{5})(?:_[0-9a-f]{4})?_html/?$`)
+
+// LinkSource finds links to rankings (their _generale.html page)
+type LinkSource interface {
+	Name() string
+	Links() ([]string, error)
+}
+
+// SourceResult reports which links a source found
+type SourceResult struct {
+	Source string   `json:"source"`
+	Links  []string `json:"links"`
+	Error  string   `json:"error,omitempty"`
+}
+
+// DiscoverRankingsLinks runs every source and merges their links, without duplicates
+func DiscoverRankingsLinks(sources []LinkSource) ([]string, []SourceResult) {
+	results := make([]SourceResult, len(sources))
+	wg := sync.WaitGroup{}
+	for i, s := range sources {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			links, err := s.Links()
+			results[i] = SourceResult{Source: s.Name(), Links: links}
+			if results[i].Links == nil {
+				results[i].Links = []string{}
+			}
+			if err != nil {
+				results[i].Error = err.Error()
+			}
+		}()
+	}
+	wg.Wait()
+
+	links := []string{}
+	for _, r := range results {
+		if r.Error != "" {
+			slog.Error("[link-discovery] source failed", "source", r.Source, "found", len(r.Links), "error", r.Error)
+		} else {
+			slog.Info("[link-discovery] source finished", "source", r.Source, "found", len(r.Links))
+		}
+		links = utils.MergeUnique(links, r.Links)
+	}
+
+	return links, results
+}
+
+// newsSource finds rankings links in the news of a news page (avvisi or its english version)
+type newsSource struct {
+	name           string
+	newsUrl        string
+	rankingsDomain string
+}
+
+func NewNewsSource(name, newsUrl, rankingsDomain string) LinkSource {
+	return &newsSource{name: name, newsUrl: newsUrl, rankingsDomain: rankingsDomain}
+}
+
+func (s *newsSource) Name() string { return s.name }
+
+func (s *newsSource) Links() ([]string, error) {
+	page, res, _, err := utils.LoadHttpHtml(s.newsUrl)
+	if err != nil {
+		return nil, fmt.Errorf("error while loading news page %s: %w", s.newsUrl, err)
+	}
+
+	newsLinks := make([]string, 0)
+	page.Find(".news .card a.btn").Each(func(_ int, e *goquery.Selection) {
+		title, _ := e.Attr("title")
+		href, _ := e.Attr("href")
+		if isRankingsNews(title) {
+			link := utils.PatchRelativeHref(href, res.Request.URL)
+			newsLinks = append(newsLinks, link)
+		}
+	})
+
+	mu := sync.Mutex{}
+	wg := sync.WaitGroup{}
+	rankingsLinks := make([]string, 0)
+	for _, link := range newsLinks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			page, res, _, err := utils.LoadHttpHtml(link)
+			if err != nil {
+				slog.Error("Error while loading a news page, skipping...", "url", link, "error", err)
+				return
+			}
+
+			links := rankingsLinksInPage(page.Find(".news-text-wrap"), res.Request.URL, s.rankingsDomain)
+			mu.Lock()
+			rankingsLinks = append(rankingsLinks, links...)
+			mu.Unlock()
+		}()
+	}
+
+	wg.Wait()
+	return rankingsLinks, nil
+}
+
+func isRankingsNews(str string) bool {
+	newsTesters := []string{
+		"graduatorie", "graduatoria", "punteggi",
+		"immatricolazioni", "immatricolazione", "punteggio",
+		"matricola", "nuovi studenti",
+		// english news page
+		"ranking", "admission results", "enrolment", "enrollment", "scores", "new students",
+	}
+
+	str = strings.ToLower(str)
+	for _, tester := range newsTesters {
+		if strings.Contains(str, tester) {
+			return true
+		}
+	}
+
+	// as a whole word, "tol" is contained in too many words (titolo, ...)
+	return slices.ContainsFunc(strings.FieldsFunc(str, isNotLetter), func(w string) bool { return w == "tol" })
+}
+
+func isNotLetter(r rune) bool {
+	return !unicode.IsLetter(r)
+}
+
+// rankingsLinksInPage returns the links of the selection pointing to the rankings domain
+func rankingsLinksInPage(sel *goquery.Selection, pageUrl *url.URL, rankingsDomain string) []string {
+	links := []string{}
+	sel.Find("a[href]").Each(func(_ int, e *goquery.Selection) {
+		href, _ := e.Attr("href")
+		u, err := url.Parse(href)
+		if err != nil {
+			slog.Error("Error while parsing the url of an <a> tag", "url", pageUrl.String(), "href", href, "error", err)
+			return
+		}
+
+		if u.Host == rankingsDomain {
+			links = append(links, utils.PatchRelativeHref(href, pageUrl))
+		}
+	})
+
+	return links
+}
+
+// pagesSource finds rankings links anywhere in a list of pages (e.g. the admission pages of the schools)
+type pagesSource struct {
+	name           string
+	pageUrls       []string
+	rankingsDomain string
+}
+
+func NewPagesSource(name string, pageUrls []string, rankingsDomain string) LinkSource {
+	return &pagesSource{name: name, pageUrls: pageUrls, rankingsDomain: rankingsDomain}
+}
+
+func (s *pagesSource) Name() string { return s.name }
+
+func (s *pagesSource) Links() ([]string, error) {
+	links := []string{}
+	errs := []error{}
+	for _, pageUrl := range s.pageUrls {
+		page, res, _, err := utils.LoadHttpHtml(pageUrl)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error while loading %s: %w", pageUrl, err))
+			continue
+		}
+
+		links = append(links, rankingsLinksInPage(page.Selection, res.Request.URL, s.rankingsDomain)...)
+	}
+
+	return links, errors.Join(errs...)
+}
+
+// directorySource reads the directory listing of the rankings domain, if the web server exposes it
+type directorySource struct {
+	rankingsDomain string
+}
+
+func NewDirectorySource(rankingsDomain string) LinkSource {
+	return &directorySource{rankingsDomain: rankingsDomain}
+}
+
+func (s *directorySource) Name() string { return LinkSourceDirectory }
+
+func (s *directorySource) Links() ([]string, error) {
+	listingUrl := "https://" + s.rankingsDomain + "/"
+	page, _, _, err := utils.LoadHttpHtml(listingUrl)
+	if err != nil {
+		return nil, fmt.Errorf("error while loading directory listing %s: %w", listingUrl, err)
+	}
+
+	links := []string{}
+	page.Find("a[href]").Each(func(_ int, e *goquery.Selection) {
+		folder := e.AttrOr("href", "")
+		match := rankingFolderRegex.FindStringSubmatch(folder)
+		if match == nil {
+			return
+		}
+
+		// same format of the bruteforce links
+		folder = strings.Trim(folder, "/")
+		links = append(links, fmt.Sprintf("https://%s/%s/%s_generale.html", s.rankingsDomain, folder, match[1]))
+	})
+
+	return links, nil
+}
+
+// manualSource reads the links added by hand in the links folder, for rankings not linked anywhere
+type manualSource struct {
+	writer writer.Writer[[]string]
+}
+
+func NewManualSource(absLinksDir string) LinkSource {
+	return &manualSource{writer: writer.NewWriter[[]string](absLinksDir)}
+}
+
+func (s *manualSource) Name() string { return LinkSourceManual }
+
+func (s *manualSource) Links() ([]string, error) {
+	links, err := s.writer.JsonRead(constants.OutputManualLinksFilename)
+	if errors.Is(err, os.ErrNotExist) {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error while reading %s: %w", constants.OutputManualLinksFilename, err)
+	}
+
+	return slices.DeleteFunc(links, func(l string) bool { return strings.TrimSpace(l) == "" }), nil
+}
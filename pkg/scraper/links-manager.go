@@ -135,6 +135,10 @@ func (lm *LinksManager) FilterNewLinks(links []string) []string {
 	return filtered
 }
 
+func (lm *LinksManager) NewScrapedLinks() []string {
+	return lm.newScrapedLinks
+}
+
 func (lm *LinksManager) SetNewLinks(scraped, broken []string) {
 	lm.newScrapedLinks = lm.FilterNewLinks(scraped)
 	lm.newBrokenLinks = lm.FilterNewLinks(broken)
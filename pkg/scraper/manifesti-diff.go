@@ -0,0 +1,172 @@
+package scraper
+
+import (
+	"cmp"
+	"log/slog"
+	"maps"
+	"net/url"
+	"slices"
+	"strconv"
+)
+
+// ManifestoCourse is a course of a manifesti list, with all its locations
+type ManifestoCourse struct {
+	Code       uint64   `json:"code"`
+	Name       string   `json:"name"`
+	DegreeType string   `json:"type"`
+	Url        string   `json:"url"`
+	Locations  []string `json:"locations"`
+
+	school   string
+	year     uint16
+	language string
+	duration uint8
+}
+
+type FieldChange struct {
+	Field string `json:"field"` // json name of the Manifesto field
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+type ManifestoChange struct {
+	Code             uint64        `json:"code"`
+	Name             string        `json:"name"` // name in To
+	Fields           []FieldChange `json:"fields"`
+	LocationsAdded   []string      `json:"locationsAdded"`
+	LocationsRemoved []string      `json:"locationsRemoved"`
+}
+
+// ManifestiDiff is the delta between two manifesti lists
+type ManifestiDiff struct {
+	From    string            `json:"from"` // sources, see parser.LoadManifestiSource
+	To      string            `json:"to"`
+	Added   []ManifestoCourse `json:"added"`
+	Removed []ManifestoCourse `json:"removed"`
+	Changed []ManifestoChange `json:"changed"`
+}
+
+func (d *ManifestiDiff) Equal() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+func (d *ManifestiDiff) Log() {
+	for _, c := range d.Added {
+		slog.Info("[manifesti-diff] added", "code", c.Code, "name", c.Name, "type", c.DegreeType, "locations", c.Locations)
+	}
+	for _, c := range d.Removed {
+		slog.Info("[manifesti-diff] removed", "code", c.Code, "name", c.Name, "type", c.DegreeType, "locations", c.Locations)
+	}
+	for _, c := range d.Changed {
+		slog.Info("[manifesti-diff] changed", "code", c.Code, "name", c.Name, "fields", c.Fields,
+			"locations_added", c.LocationsAdded, "locations_removed", c.LocationsRemoved)
+	}
+	slog.Info("[manifesti-diff] summary", "from", d.From, "to", d.To, "equal", d.Equal(),
+		"added", len(d.Added), "removed", len(d.Removed), "changed", len(d.Changed))
+}
+
+// DiffManifesti compares two manifesti lists course by course (k_corso_la code).
+// If a list has more academic years of a course, only the latest one is compared.
+// Urls are compared without host, since Politecnico loves changing domains and web servers.
+// School, language and duration are compared only if both lists have them (older lists don't).
+// The academic year is not compared: it changes every year, and it is not a change of the course.
+func DiffManifesti(fromName string, from []Manifesto, toName string, to []Manifesto) ManifestiDiff {
+	diff := ManifestiDiff{
+		From:    fromName,
+		To:      toName,
+		Added:   []ManifestoCourse{},
+		Removed: []ManifestoCourse{},
+		Changed: []ManifestoChange{},
+	}
+
+	fromCourses, toCourses := manifestoCourses(from), manifestoCourses(to)
+	for _, code := range slices.Sorted(maps.Keys(fromCourses)) {
+		if _, ok := toCourses[code]; !ok {
+			diff.Removed = append(diff.Removed, *fromCourses[code])
+		}
+	}
+
+	for _, code := range slices.Sorted(maps.Keys(toCourses)) {
+		t := toCourses[code]
+		f, ok := fromCourses[code]
+		if !ok {
+			diff.Added = append(diff.Added, *t)
+			continue
+		}
+
+		if change := diffManifestoCourse(f, t); len(change.Fields) > 0 || len(change.LocationsAdded) > 0 || len(change.LocationsRemoved) > 0 {
+			diff.Changed = append(diff.Changed, change)
+		}
+	}
+
+	return diff
+}
+
+func manifestoCourses(mans []Manifesto) map[uint64]*ManifestoCourse {
+	courses := map[uint64]*ManifestoCourse{}
+	for _, m := range mans {
+		code := m.CourseCode()
+		c, ok := courses[code]
+		if ok && m.Year < c.year {
+			continue
+		}
+		if !ok || m.Year > c.year {
+			c = &ManifestoCourse{Code: code, Name: m.Name, DegreeType: m.DegreeType, Url: m.Url, Locations: []string{},
+				school: m.School, year: m.Year, language: m.Language, duration: m.Duration}
+			courses[code] = c
+		}
+
+		if !slices.Contains(c.Locations, m.Location) {
+			c.Locations = append(c.Locations, m.Location)
+		}
+	}
+
+	for _, c := range courses {
+		slices.Sort(c.Locations)
+	}
+	return courses
+}
+
+func diffManifestoCourse(from, to *ManifestoCourse) ManifestoChange {
+	change := ManifestoChange{Code: to.Code, Name: to.Name, Fields: []FieldChange{}, LocationsAdded: []string{}, LocationsRemoved: []string{}}
+	field := func(name, a, b string, compare bool) {
+		if compare && a != b {
+			change.Fields = append(change.Fields, FieldChange{Field: name, From: a, To: b})
+		}
+	}
+
+	field("name", from.Name, to.Name, true)
+	field("type", from.DegreeType, to.DegreeType, true)
+	field("url", comparableUrl(from.Url), comparableUrl(to.Url), true)
+	field("school", from.school, to.school, from.school != "" && to.school != "")
+	field("language", from.language, to.language, from.language != "" && to.language != "")
+	field("duration", strconv.Itoa(int(from.duration)), strconv.Itoa(int(to.duration)), from.duration != 0 && to.duration != 0)
+
+	for _, l := range to.Locations {
+		if !slices.Contains(from.Locations, l) {
+			change.LocationsAdded = append(change.LocationsAdded, l)
+		}
+	}
+	for _, l := range from.Locations {
+		if !slices.Contains(to.Locations, l) {
+			change.LocationsRemoved = append(change.LocationsRemoved, l)
+		}
+	}
+
+	slices.SortFunc(change.Fields, func(a, b FieldChange) int { return cmp.Compare(a.Field, b.Field) })
+	return change
+}
+
+// comparableUrl drops the host and the academic year (aa query parameter) from the url,
+// since the manifesto of every year has its own url
+func comparableUrl(rawUrl string) string {
+	u, err := url.Parse(rawUrl)
+	if err != nil {
+		return rawUrl
+	}
+
+	q := u.Query()
+	q.Del("aa")
+	u.Scheme, u.Host, u.RawQuery = "", "", q.Encode()
+	return u.String()
+}
@@ -0,0 +1,292 @@
+package scraper
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/utils"
+	"github.com/PuerkitoBio/goquery"
+)
+
+// ErrLayoutChanged is returned when every discovery strategy of a step failed:
+// the Polimi website changed and the strategies must be updated
+var ErrLayoutChanged = errors.New("layout changed, no manifesti discovery strategy worked")
+
+// at most this many course pages of the course list are crawled
+const maxCrawledCoursePages = 40
+
+type DiscoveryStep string
+
+const (
+	StepManifestoLink DiscoveryStep = "manifesto_link" // course page -> manifesto page
+	StepCourseOptions DiscoveryStep = "course_options" // manifesto page -> courses of the select element
+	StepCourseList    DiscoveryStep = "course_list"    // course list -> course pages
+)
+
+// StrategyResult is the health check of a strategy on a page
+type StrategyResult struct {
+	Step     DiscoveryStep `json:"step"`
+	Strategy string        `json:"strategy"`
+	Url      string        `json:"url"`
+	Ok       bool          `json:"ok"`
+	Found    int           `json:"found"`
+	Error    string        `json:"error,omitempty"`
+}
+
+type DiscoveryReport struct {
+	mu      sync.Mutex
+	Results []StrategyResult `json:"results"`
+}
+
+func (r *DiscoveryReport) add(res StrategyResult) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Results = append(r.Results, res)
+}
+
+func (r *DiscoveryReport) Log() {
+	for _, res := range r.Results {
+		attrs := []any{"step", res.Step, "strategy", res.Strategy, "url", res.Url, "found", res.Found}
+		if res.Ok {
+			slog.Info("[manifesti-discovery] strategy worked", attrs...)
+		} else {
+			slog.Warn("[manifesti-discovery] strategy failed", append(attrs, "error", res.Error)...)
+		}
+	}
+}
+
+type manifestoPage struct {
+	doc *goquery.Document
+	url *url.URL
+}
+
+type courseOption struct {
+	degreeType string
+	name       string
+	code       uint64
+}
+
+type manifestoLinkStrategy struct {
+	name string
+	find func(doc *goquery.Document) []string
+}
+
+type courseOptionsStrategy struct {
+	name string
+	find func(doc *goquery.Document) []courseOption
+}
+
+// strategies are tried in order, the first one finding something wins
+var (
+	manifestoLinkStrategies = []manifestoLinkStrategy{
+		{name: "frame-link", find: findFrameManifestoLink},
+		{name: "text-or-href", find: findAnyManifestoLink},
+	}
+
+	courseOptionsStrategies = []courseOptionsStrategy{
+		{name: "combocds-selector", find: findComboCdsOptions},
+		{name: "label-select", find: findLabeledSelectOptions},
+	}
+)
+
+func findFrameManifestoLink(doc *goquery.Document) []string {
+	hrefs := []string{}
+	doc.Find(".frame a").Each(func(i int, e *goquery.Selection) {
+		text := strings.ToLower(e.Text())
+		href, ok := e.Attr("href")
+		if strings.Contains(text, "piano di studi") && ok {
+			hrefs = append(hrefs, href)
+		}
+	})
+	return hrefs
+}
+
+func findAnyManifestoLink(doc *goquery.Document) []string {
+	hrefs := []string{}
+	doc.Find("a[href]").Each(func(i int, e *goquery.Selection) {
+		text := strings.ToLower(e.Text())
+		href := e.AttrOr("href", "")
+		if strings.Contains(strings.ToLower(href), "manifestopublic.do") ||
+			strings.Contains(text, "piano di studi") || strings.Contains(text, "study plan") {
+			hrefs = append(hrefs, href)
+		}
+	})
+	return hrefs
+}
+
+func findComboCdsOptions(doc *goquery.Document) []courseOption {
+	return selectOptions(doc.Find("#id_combocds > tbody > tr:nth-child(3) > td.ElementInfoCard2.left > select").First())
+}
+
+// findLabeledSelectOptions looks for the course select by its name or by the label of its row
+func findLabeledSelectOptions(doc *goquery.Document) []courseOption {
+	sel := doc.Find("select[name=k_corso_la]").First()
+	if sel.Length() == 0 {
+		doc.Find("select").EachWithBreak(func(i int, s *goquery.Selection) bool {
+			label := strings.ToLower(s.Closest("tr").Text())
+			if strings.Contains(label, "corso di studi") || strings.Contains(label, "course of study") {
+				sel = s
+				return false
+			}
+			return true
+		})
+	}
+
+	return selectOptions(sel)
+}
+
+// selectOptions reads the courses of the select, grouped by degree type in optgroups
+func selectOptions(sel *goquery.Selection) []courseOption {
+	options := []courseOption{}
+	sel.Find("option").Each(func(i int, opt *goquery.Selection) {
+		code, err := strconv.ParseUint(opt.AttrOr("value", ""), 10, 64)
+		if err != nil || code == 0 {
+			return // placeholder option
+		}
+
+		degreeType := opt.ParentFiltered("optgroup").AttrOr("label", "")
+		options = append(options, courseOption{
+			degreeType: strings.TrimSpace(strings.Split(degreeType, " -")[0]),
+			name:       strings.TrimSpace(strings.Split(opt.Text(), " (")[0]),
+			code:       code,
+		})
+	})
+	return options
+}
+
+// discoverManifestoPage loads the manifesto page linked by a course page
+func discoverManifestoPage(courseUrl string, report *DiscoveryReport) (*manifestoPage, error) {
+	doc, res, _, err := utils.LoadHttpHtml(courseUrl)
+	if err != nil {
+		report.add(StrategyResult{Step: StepManifestoLink, Strategy: "load", Url: courseUrl, Error: err.Error()})
+		return nil, err
+	}
+
+	for _, s := range manifestoLinkStrategies {
+		hrefs := s.find(doc)
+		result := StrategyResult{Step: StepManifestoLink, Strategy: s.name, Url: courseUrl, Found: len(hrefs)}
+		if len(hrefs) == 0 {
+			result.Error = "no manifesto link"
+			report.add(result)
+			continue
+		}
+
+		// the last matching link, as the original selector did
+		mdoc, mres, _, err := utils.LoadHttpHtml(utils.PatchRelativeHref(hrefs[len(hrefs)-1], res.Request.URL))
+		if err != nil {
+			result.Error = err.Error()
+			report.add(result)
+			continue
+		}
+
+		result.Ok = true
+		report.add(result)
+		return &manifestoPage{doc: mdoc, url: mres.Request.URL}, nil
+	}
+
+	return nil, fmt.Errorf("%w: manifesto link not found in %s", ErrLayoutChanged, courseUrl)
+}
+
+// discoverManifestoPages returns the manifesto pages of the school course pages. If any of them
+// fails, the course list is crawled as well, to find the manifesto pages of the failed schools
+func discoverManifestoPages(schoolUrls []string, courseListUrl string, report *DiscoveryReport) []*manifestoPage {
+	pages := []*manifestoPage{}
+	seen := map[string]bool{} // many courses link the same manifesto page
+	failed := []string{}
+	for _, u := range schoolUrls {
+		p, err := discoverManifestoPage(u, report)
+		if err != nil {
+			failed = append(failed, u)
+			continue
+		}
+
+		if key := p.url.String(); !seen[key] {
+			seen[key] = true
+			pages = append(pages, p)
+		}
+	}
+
+	if (len(failed) == 0 && len(schoolUrls) > 0) || courseListUrl == "" {
+		return pages
+	}
+
+	slog.Warn("[manifesti-discovery] some school course pages did not work, crawling the course list", "url", courseListUrl, "failed", failed)
+	for _, u := range crawlCoursePages(courseListUrl, report) {
+		p, err := discoverManifestoPage(u, report)
+		if err != nil {
+			continue
+		}
+
+		if key := p.url.String(); !seen[key] {
+			seen[key] = true
+			pages = append(pages, p)
+		}
+	}
+
+	return pages
+}
+
+// crawlCoursePages returns the course pages linked by the course list page
+func crawlCoursePages(courseListUrl string, report *DiscoveryReport) []string {
+	res := StrategyResult{Step: StepCourseList, Strategy: "course-list-crawl", Url: courseListUrl}
+	doc, httpRes, _, err := utils.LoadHttpHtml(courseListUrl)
+	if err != nil {
+		res.Error = err.Error()
+		report.add(res)
+		return nil
+	}
+
+	urls := []string{}
+	doc.Find("a[href*='dettaglio-corso']").Each(func(i int, e *goquery.Selection) {
+		href := utils.PatchRelativeHref(e.AttrOr("href", ""), httpRes.Request.URL)
+		if len(urls) < maxCrawledCoursePages && !slices.Contains(urls, href) {
+			urls = append(urls, href)
+		}
+	})
+
+	res.Found, res.Ok = len(urls), len(urls) > 0
+	if !res.Ok {
+		res.Error = "no course page link"
+	}
+	report.add(res)
+	return urls
+}
+
+// discoverCourseOptions returns the courses listed by a manifesto page
+func discoverCourseOptions(page *manifestoPage, report *DiscoveryReport) []courseOption {
+	for _, s := range courseOptionsStrategies {
+		options := s.find(page.doc)
+		res := StrategyResult{Step: StepCourseOptions, Strategy: s.name, Url: page.url.String(), Found: len(options), Ok: len(options) > 0}
+		if !res.Ok {
+			res.Error = "no course options"
+		}
+		report.add(res)
+
+		if res.Ok {
+			return options
+		}
+	}
+
+	return nil
+}
+
+// CheckManifestiDiscovery runs the discovery strategies, without scraping the manifesti,
+// and returns ErrLayoutChanged if no course was found
+func CheckManifestiDiscovery(schoolUrls []string, courseListUrl string) (*DiscoveryReport, error) {
+	report := &DiscoveryReport{Results: []StrategyResult{}}
+	courses := 0
+	for _, p := range discoverManifestoPages(schoolUrls, courseListUrl, report) {
+		courses += len(discoverCourseOptions(p, report))
+	}
+
+	if courses == 0 {
+		return report, fmt.Errorf("%w: no course found", ErrLayoutChanged)
+	}
+	return report, nil
+}
@@ -0,0 +1,130 @@
+package scraper
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/utils"
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/writer"
+)
+
+// ManifestiSnapshot is the list of manifesti of an academic year, as scraped on Date.
+// Snapshots are saved in <snapshots folder>/<year>/<date>.json
+type ManifestiSnapshot struct {
+	Year      uint16
+	Date      utils.Date
+	Manifesti []Manifesto
+}
+
+// CourseCode returns the k_corso_la of the manifesto, also for manifesti scraped by older versions
+func (m *Manifesto) CourseCode() uint64 {
+	return m.key().code
+}
+
+// WriteManifestiSnapshots saves a snapshot for each academic year of mans, dated today.
+// mans must be the manifesti listed in this run (see ScrapeManifesti), not the accumulated
+// list, otherwise the courses no longer published would never leave the snapshots.
+// A year is skipped if its latest snapshot has the same manifesti.
+// Manifesti without year (scraped by older versions) are not saved.
+func WriteManifestiSnapshots(absDir string, mans []Manifesto, now time.Time) (int, error) {
+	byYear := map[uint16][]Manifesto{}
+	for _, m := range mans {
+		if m.Year != 0 {
+			byYear[m.Year] = append(byYear[m.Year], m)
+		}
+	}
+
+	existing, err := LoadManifestiSnapshots(absDir)
+	if err != nil {
+		return 0, err
+	}
+
+	latest := map[uint16]ManifestiSnapshot{}
+	for _, s := range existing {
+		latest[s.Year] = s // sorted by date
+	}
+
+	written := 0
+	for year, list := range byYear {
+		sortManifesti(list)
+		if prev, ok := latest[year]; ok && reflect.DeepEqual(prev.Manifesti, list) {
+			continue
+		}
+
+		w := writer.NewWriter[[]Manifesto](path.Join(absDir, strconv.Itoa(int(year))))
+		if err := w.JsonWrite(now.Format(utils.DateLayout)+".json", list, true); err != nil {
+			return written, fmt.Errorf("error while writing manifesti snapshot of %d: %w", year, err)
+		}
+		written++
+	}
+
+	return written, nil
+}
+
+// LoadManifestiSnapshots reads every snapshot, sorted by year and date.
+// A missing folder means no snapshots.
+func LoadManifestiSnapshots(absDir string) ([]ManifestiSnapshot, error) {
+	snapshots := []ManifestiSnapshot{}
+	if ok, _ := utils.DoFolderExists(absDir); !ok {
+		return snapshots, nil
+	}
+
+	years, err := utils.GetEntriesInFolder(absDir)
+	if err != nil {
+		return nil, fmt.Errorf("could not read manifesti snapshots folder: %w", err)
+	}
+
+	for _, y := range years {
+		year, err := strconv.ParseUint(y.Name(), 10, 16)
+		if !y.IsDir() || err != nil {
+			continue
+		}
+
+		files, err := utils.GetEntriesInFolder(path.Join(absDir, y.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("could not read manifesti snapshots of %d: %w", year, err)
+		}
+
+		for _, f := range files {
+			rawDate, ok := strings.CutSuffix(f.Name(), ".json")
+			if f.IsDir() || !ok {
+				continue
+			}
+
+			date, err := time.Parse(utils.DateLayout, rawDate)
+			if err != nil {
+				continue
+			}
+
+			data, err := os.ReadFile(path.Join(absDir, y.Name(), f.Name()))
+			if err != nil {
+				return nil, err
+			}
+
+			s := ManifestiSnapshot{Year: uint16(year), Date: utils.Date{Time: date}}
+			if err := json.Unmarshal(data, &s.Manifesti); err != nil {
+				return nil, fmt.Errorf("could not decode manifesti snapshot %d/%s: %w", year, f.Name(), err)
+			}
+			snapshots = append(snapshots, s)
+		}
+	}
+
+	slices.SortFunc(snapshots, func(a, b ManifestiSnapshot) int {
+		return cmp.Or(cmp.Compare(a.Year, b.Year), a.Date.Compare(b.Date.Time))
+	})
+	return snapshots, nil
+}
+
+func sortManifesti(mans []Manifesto) {
+	slices.SortStableFunc(mans, func(a, b Manifesto) int {
+		return cmp.Or(cmp.Compare(a.School, b.School), cmp.Compare(a.Year, b.Year), cmp.Compare(a.Name, b.Name), cmp.Compare(a.Location, b.Location))
+	})
+}
@@ -1,15 +1,14 @@
 package scraper
 
 import (
-	"log"
+	"fmt"
 	"log/slog"
-	"reflect"
-	"slices"
+	"net/url"
+	"regexp"
 	"strconv"
 	"strings"
 	"sync"
 
-	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/constants"
 	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/utils"
 	"github.com/PuerkitoBio/goquery"
 )
@@ -19,119 +18,267 @@ type Manifesto struct {
 	Url        string `json:"url"`
 	Location   string `json:"location"`
 	DegreeType string `json:"type"`
+
+	// fields below are empty in manifesti scraped by older versions (run the scraper with -f)
+
+	School   string `json:"school"`   // owning school, e.g. "Ingegneria Industriale e dell'Informazione"
+	Year     uint16 `json:"year"`     // first year of the academic year, e.g. 2024 for 2024/2025
+	Code     uint64 `json:"code"`     // k_corso_la query parameter, unique per course
+	Language string `json:"language"` // "IT", "EN" or "IT,EN", as in the rankings phase
+	Duration uint8  `json:"duration"` // nominal duration in years
+}
+
+// manifestoKey identifies a manifesto: the same course is listed by every school page sharing it
+type manifestoKey struct {
+	code     uint64
+	year     uint16
+	location string
+}
+
+func (m *Manifesto) key() manifestoKey {
+	code := m.Code
+	if code == 0 {
+		code = courseCodeFromUrl(m.Url)
+	}
+	return manifestoKey{code: code, year: m.Year, location: m.Location}
+}
+
+func courseCodeFromUrl(rawUrl string) uint64 {
+	u, err := url.Parse(rawUrl)
+	if err != nil {
+		return 0
+	}
+
+	code, _ := strconv.ParseUint(u.Query().Get("k_corso_la"), 10, 64)
+	return code
 }
 
-func ScrapeManifesti(alreadyScraped []Manifesto) []Manifesto {
-	urls := []string{constants.WebPolimiDesignUrl, constants.WebPolimiArchUrbUrl, constants.WebPolimiIngCivUrl, constants.WebPolimiIngInfIndUrl}
-	// hrefs := []string{}
+// schoolUrls are course pages (one per school) used to obtain each school's manifesto link,
+// see constants.WebPolimiDesignUrl and the config urls.schoolCoursePages field.
+// If any of them fails, the course pages linked by courseListUrl are crawled too (see manifesti-discovery.go).
+// Every loaded manifesto page is saved in absHtmlDir (see StudyPlanFilename), if not empty.
+// Returns every manifesto (alreadyScraped ones too) and the ones listed by the pages in this run,
+// which miss the courses no longer published.
+// Returns ErrLayoutChanged, with the already scraped manifesti, if no course was found.
+func ScrapeManifesti(alreadyScraped []Manifesto, schoolUrls []string, courseListUrl, absHtmlDir string) (mans []Manifesto, listed []Manifesto, err error) {
+	if absHtmlDir != "" {
+		if err := utils.CreateFolderIfNotExists(absHtmlDir); err != nil {
+			return alreadyScraped, nil, err
+		}
+	}
+
 	out := alreadyScraped
+	mu := sync.Mutex{} // out, seenUrls and listedUrls are used by every page goroutine
+	report := &DiscoveryReport{Results: []StrategyResult{}}
 
 	wg := sync.WaitGroup{}
 
-	alreadyScrapedUrl := make([]string, len(alreadyScraped))
-	for i, as := range alreadyScraped {
-		alreadyScrapedUrl[i] = as.Url
+	// urls already scraped, or being scraped from another manifesto page
+	seenUrls := make(map[string]bool, len(alreadyScraped))
+	for _, as := range alreadyScraped {
+		seenUrls[as.Url] = true
 	}
+	listedUrls := map[string]bool{}
+
+	courses := 0
+	for _, page := range discoverManifestoPages(schoolUrls, courseListUrl, report) {
+		options := discoverCourseOptions(page, report)
+		courses += len(options)
 
-	for _, url := range urls {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			doc, res, _, err := utils.LoadHttpHtml(url)
-			if err != nil {
-				log.Fatalf("Error while loading school url %s. err: %w", url, err)
-			}
+			for _, opt := range options {
+				optUrl := *page.url
+				q := optUrl.Query()
+				q.Set("k_corso_la", strconv.FormatUint(opt.code, 10))
+				q.Del("__pj1")
+				q.Del("__pj0")
+				optUrl.RawQuery = q.Encode()
 
-			var manHref string
-			doc.Find(".frame a").Each(func(i int, e *goquery.Selection) {
-				text := strings.ToLower(e.Text())
-				href, ok := e.Attr("href")
-				if strings.Contains(text, "piano di studi") && ok {
-					manHref = href
+				mu.Lock()
+				seen := seenUrls[optUrl.String()]
+				seenUrls[optUrl.String()] = true
+				listedUrls[optUrl.String()] = true
+				mu.Unlock()
+				if seen {
+					slog.Debug("url already scraped, skipping...", "url", optUrl.String())
+					continue
 				}
-			})
 
-			doc, res, _, err = utils.LoadHttpHtml(manHref)
-			if err != nil {
-				log.Fatalf("Error while loading manifest url %s. err: %w", manHref, err)
-			}
+				slog.Debug("found new manifesti url, scraping...", "url", optUrl.String())
+				mandoc, _, data, err := utils.LoadHttpHtml(optUrl.String())
+				if err != nil {
+					slog.Error("could not load manifesto page", "url", optUrl.String(), "error", err)
+					continue
+				}
 
-			finalUrl := res.Request.URL
-			doc.Find("#id_combocds > tbody > tr:nth-child(3) > td.ElementInfoCard2.left > select > optgroup").Each(func(i int, group *goquery.Selection) {
-				degreeType, ok := group.Attr("label")
-				if !ok {
-					return
+				info := parseManifestoInfo(mandoc, &optUrl)
+				if absHtmlDir != "" && len(info.locations) > 0 {
+					// same page (and filename) for every location
+					if err := saveStudyPlanPage(absHtmlDir, Manifesto{Code: opt.code, Year: info.year}, data); err != nil {
+						slog.Error("could not save manifesto page", "url", optUrl.String(), "error", err)
+					}
 				}
+				for _, location := range info.locations {
+					newMan := Manifesto{
+						Name:       opt.name,
+						Url:        optUrl.String(),
+						Location:   location,
+						DegreeType: opt.degreeType,
+						School:     info.school,
+						Year:       info.year,
+						Code:       opt.code,
+						Language:   info.language,
+						Duration:   info.duration,
+					}
 
-				degreeType = strings.Split(degreeType, " -")[0]
+					mu.Lock()
+					out = append(out, newMan)
+					mu.Unlock()
+				}
+			}
+		}()
+	}
 
-				group.Children().Each(func(i int, opt *goquery.Selection) {
-					courseName := opt.Text()
-					courseName = strings.Split(courseName, " (")[0]
+	wg.Wait()
+	report.Log()
 
-					value, err := strconv.ParseUint(opt.AttrOr("value", "0"), 10, 64)
-					if err != nil {
-						log.Fatal(err)
-					}
+	if courses == 0 {
+		return alreadyScraped, nil, fmt.Errorf("%w: no course found in the manifesto pages", ErrLayoutChanged)
+	}
 
-					optUrl := *finalUrl
-					q := optUrl.Query()
-					q.Set("k_corso_la", strconv.FormatUint(value, 10))
-					q.Del("__pj1")
-					q.Del("__pj0")
-					optUrl.RawQuery = q.Encode()
+	mans = dedupManifesti(out)
+	listed = make([]Manifesto, 0, len(mans))
+	for _, m := range mans {
+		if listedUrls[m.Url] {
+			listed = append(listed, m)
+		}
+	}
 
-					if slices.Contains(alreadyScrapedUrl, optUrl.String()) {
-						slog.Debug("url already scraped, skipping...", "url", optUrl.String())
-						return
-					}
+	return mans, listed, nil
+}
 
-					slog.Debug("found new manifesti url, scraping...", "url", optUrl.String())
-					mandoc, _, _, err := utils.LoadHttpHtml(optUrl.String())
-					if err != nil {
-						log.Fatal(err)
-					}
+// dedupManifesti removes the courses shared between schools, which are listed by each of them
+// (e.g. Design & Engineering (Des, 3I), Geoinformatics Engineering (3I, IngCiv)).
+// They have the same course code, and the owning school is read from the manifesto page itself.
+func dedupManifesti(mans []Manifesto) []Manifesto {
+	seen := make(map[manifestoKey]bool, len(mans))
+	out := make([]Manifesto, 0, len(mans))
+	for _, m := range mans {
+		key := m.key()
+		if seen[key] {
+			slog.Debug("scraper manifesti: found duplicate", "manifesto", m)
+			continue
+		}
 
-					mandoc.Find("td.CenterBar table.BoxInfoCard tr:nth-child(4) td:nth-child(4)").First().Each(func(i int, loc *goquery.Selection) {
-						locations := strings.Split(loc.Text(), ",")
-						for _, location := range locations {
-							newMan := Manifesto{
-								Name:       strings.TrimSpace(courseName),
-								Url:        optUrl.String(),
-								Location:   strings.TrimSpace(location),
-								DegreeType: strings.TrimSpace(degreeType),
-							}
-							out = append(out, newMan)
-						}
-					})
-				})
-			})
-		}()
+		seen[key] = true
+		out = append(out, m)
 	}
 
-	wg.Wait()
+	// goroutines append in any order
+	sortManifesti(out)
+	return out
+}
+
+type manifestoInfo struct {
+	school    string
+	year      uint16
+	language  string
+	duration  uint8
+	locations []string
+}
+
+var (
+	academicYearRegex = regexp.MustCompile(`(\d{4})\s*[/-]\s*\d{2,4}`)
+	firstNumberRegex  = regexp.MustCompile(`\d+`)
+)
+
+// parseManifestoInfo reads the info card of a manifesto page, made of "label | value" cells
+func parseManifestoInfo(doc *goquery.Document, pageUrl *url.URL) manifestoInfo {
+	info := manifestoInfo{locations: []string{}}
 
-	// because of there are some courses shared between schools, they appears twice
-	// in the list, while we want them only once.
-	// In the future we could also track the School, so it would not cause the issue.
-	// e.g. Design & Engineering (Des, 3I), Geoinformatics Engineering (3I, IngCiv)
-	cleanOut := make([]Manifesto, 0, len(out))
-	for i, m1 := range out[:] {
-		count := 0
-		for _, m2 := range out[i+1:] {
-			if reflect.DeepEqual(m1, m2) {
-				count++
+	doc.Find("td.CenterBar table.BoxInfoCard tr").Each(func(i int, row *goquery.Selection) {
+		cells := row.Find("td")
+		for j := 0; j+1 < cells.Length(); j += 2 {
+			label := strings.ToLower(strings.TrimSpace(cells.Eq(j).Text()))
+			value := strings.TrimSpace(cells.Eq(j + 1).Text())
+			switch {
+			case value == "":
+			case strings.Contains(label, "scuola") || strings.Contains(label, "school"):
+				info.school = stripSchoolPrefix(value)
+			case strings.Contains(label, "anno accademico") || strings.Contains(label, "academic year"):
+				info.year = parseAcademicYear(value)
+			case strings.Contains(label, "lingua") || strings.Contains(label, "language"):
+				info.language = normalizeLanguage(value)
+			case strings.Contains(label, "durata") || strings.Contains(label, "duration"):
+				if n, err := strconv.ParseUint(firstNumberRegex.FindString(value), 10, 8); err == nil {
+					info.duration = uint8(n)
+				}
+			case strings.Contains(label, "sede") || strings.Contains(label, "campus"):
+				info.locations = splitLocations(value)
 			}
 		}
+	})
+
+	if len(info.locations) == 0 {
+		// position of the location cell, before the info card was read by label
+		loc := doc.Find("td.CenterBar table.BoxInfoCard tr:nth-child(4) td:nth-child(4)").First()
+		info.locations = splitLocations(loc.Text())
+	}
 
-		if count == 0 {
-			cleanOut = append(cleanOut, m1)
-		} else {
-			// found a duplicate, not adding.
-			// it will be added when m1 -> m2 -> ... -> mn, with mn last duplicate
-			slog.Debug("scraper manifesti: found duplicate", "manifesto", m1)
+	if info.year == 0 {
+		// the academic year is also a query parameter, e.g. aa=2024
+		if year, err := strconv.ParseUint(pageUrl.Query().Get("aa"), 10, 16); err == nil {
+			info.year = uint16(year)
 		}
 	}
 
-	return cleanOut
+	return info
+}
+
+func stripSchoolPrefix(s string) string {
+	for _, prefix := range []string{"Scuola di ", "School of "} {
+		s, _ = strings.CutPrefix(s, prefix)
+	}
+	return strings.TrimSpace(s)
+}
+
+func parseAcademicYear(s string) uint16 {
+	match := academicYearRegex.FindStringSubmatch(s)
+	if match == nil {
+		return 0
+	}
+
+	year, err := strconv.ParseUint(match[1], 10, 16)
+	if err != nil {
+		return 0
+	}
+	return uint16(year)
+}
+
+func normalizeLanguage(s string) string {
+	lower := strings.ToLower(s)
+	langs := []string{}
+	if strings.Contains(lower, "ital") {
+		langs = append(langs, "IT")
+	}
+	if strings.Contains(lower, "ingl") || strings.Contains(lower, "engl") {
+		langs = append(langs, "EN")
+	}
+
+	if len(langs) == 0 {
+		return s
+	}
+	return strings.Join(langs, ",")
+}
+
+func splitLocations(s string) []string {
+	out := []string{}
+	for _, location := range strings.Split(s, ",") {
+		if location = strings.TrimSpace(location); location != "" {
+			out = append(out, location)
+		}
+	}
+	return out
 }
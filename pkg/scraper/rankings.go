@@ -1,87 +1,19 @@
 package scraper
 
 import (
-	"log"
+	"fmt"
 	"log/slog"
 	"net/url"
+	"path"
 	"strings"
 	"sync"
 
 	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/constants"
 	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/utils"
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/writer"
 	"github.com/PuerkitoBio/goquery"
 )
 
-func ScrapeRankingsLinks() []string {
-	links := scrapeAvvisiPage()
-	slog.Debug("output of scrapeAvvisiPage", "count", len(links))
-	return links
-}
-
-func scrapeAvvisiPage() []string {
-	page, res, _, err := utils.LoadHttpHtml(constants.WebPolimiAvvisiFuturiStudentiUrl)
-	if err != nil {
-		log.Fatalf("Error while loading avvisi page. url %s. err: %w", constants.WebPolimiAvvisiFuturiStudentiUrl, err)
-	}
-
-	newsLinks := make([]string, 0)
-	rankingsLinks := make([]string, 0)
-	page.Find(".news .card a.btn").Each(func(_ int, e *goquery.Selection) {
-		title, _ := e.Attr("title")
-		href, _ := e.Attr("href")
-		if isRankingsNews(title) {
-			link := utils.PatchRelativeHref(href, res.Request.URL)
-			newsLinks = append(newsLinks, link)
-		}
-	})
-
-	wg := sync.WaitGroup{}
-
-	for _, link := range newsLinks {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			page, _, _, err := utils.LoadHttpHtml(link)
-			if err != nil {
-				slog.Error("Error while loading a news page, skipping...", "url", link, "error", err)
-			}
-
-			page.Find(".news-text-wrap a").Each(func(_ int, e *goquery.Selection) {
-				href, _ := e.Attr("href")
-				url, err := url.Parse(href)
-				if err != nil {
-					slog.Error("Error while parsing the url of an article <a> tag", "url", link, "error", err)
-					return
-				}
-
-				if url.Host == constants.WebPolimiRisultatiAmmissioneDomainName {
-					link := utils.PatchRelativeHref(href, res.Request.URL)
-					rankingsLinks = append(rankingsLinks, link)
-				}
-			})
-		}()
-	}
-
-	wg.Wait()
-	return rankingsLinks
-}
-
-func isRankingsNews(str string) bool {
-	newsTesters := []string{
-		"graduatorie", "graduatoria", "punteggi", "tol",
-		"immatricolazioni", "immatricolazione", "punteggio",
-		"matricola", "nuovi studenti",
-	}
-
-	for _, tester := range newsTesters {
-		if strings.Contains(str, tester) {
-			return true
-		}
-	}
-
-	return false
-}
-
 type HtmlPage struct {
 	Id      string
 	Content []byte
@@ -91,6 +23,7 @@ type HtmlRanking struct {
 	Id        string
 	Url       *url.URL
 	Index     HtmlPage
+	Indexes   []HtmlPage // sub-index pages, the id is the table folder they link
 	ByMerit   []HtmlPage
 	ById      []HtmlPage
 	ByCourse  []HtmlPage
@@ -99,13 +32,16 @@ type HtmlRanking struct {
 
 func DownloadRankings(startingLinks []string) []HtmlRanking {
 	ws := sync.WaitGroup{}
+	mu := sync.Mutex{}
 	out := make([]HtmlRanking, 0)
 	for _, link := range startingLinks {
 		ws.Add(1)
 		go func() {
 			defer ws.Done()
 			htmlRanking := ScrapeRecursiveRankingHtmls(link)
+			mu.Lock()
 			out = append(out, htmlRanking)
+			mu.Unlock()
 		}()
 	}
 
@@ -130,55 +66,53 @@ func ScrapeRecursiveRankingHtmls(startingLink string) HtmlRanking {
 	htmlRanking.Index = HtmlPage{Id: id, Content: mainHtml}
 	count++
 
-	indexesHrefs := make([]string, 0)
-	page.Find(".titolo a").Each(func(_ int, s *goquery.Selection) {
-		href, _ := s.Attr("href")
-		indexesHrefs = append(indexesHrefs, href)
-	})
-
-	for _, href := range indexesHrefs {
+	for _, href := range RankingIndexLinks(page) {
+		// the folder must be known before downloading the table pages
+		folder, ok := RankingIndexFolder(href)
 		link := utils.PatchRelativeHref(href, res.Request.URL)
-		page, indexRes, _, err := utils.LoadHttpHtml(link)
+		if !ok {
+			slog.Error("Index not recognized, please investigate.", "index_href", href, "index_url", link)
+			continue
+		}
+
+		page, indexRes, indexHtml, err := utils.LoadHttpHtml(link)
 		if err != nil {
 			slog.Error("Error while loading ranking sub-index page.", "url", link, "error", err)
 			continue
 		}
 
-		pages := make([]HtmlPage, 0)
+		htmlRanking.Indexes = append(htmlRanking.Indexes, HtmlPage{Id: folder, Content: indexHtml})
+		count++
 
+		pages := make([]HtmlPage, 0)
+		mu := sync.Mutex{}
 		ws := sync.WaitGroup{}
-		page.Find(".TableDati td a").Each(func(_ int, e *goquery.Selection) {
+		for _, href := range RankingTableLinks(page) {
 			ws.Add(1)
 			go func() {
 				defer ws.Done()
-				href, _ := e.Attr("href")
 				link := utils.PatchRelativeHref(href, indexRes.Request.URL)
 				_, _, tableHtml, err := utils.LoadHttpHtml(link)
 				if err != nil {
-					slog.Error("Could not load ranking table page. url %s. err: %w", link, err)
+					slog.Error("Could not load ranking table page.", "url", link, "error", err)
 					return
 				}
 
+				mu.Lock()
 				pages = append(pages, HtmlPage{Id: href, Content: tableHtml})
+				mu.Unlock()
 			}()
-		})
+		}
 		ws.Wait()
 
-		// IMPORTANT!!
-		// ByCourse MUST BE THE FIRST IF STATEMENT
-		// otherwise it will match ByMerit also for ByCourse Index
-		if strings.HasSuffix(href, constants.HtmlRankingUrl_IndexSuffix_ByCourse) {
-			slog.Debug("pattern matched index href with ByCourse", "href", href)
+		slog.Debug("pattern matched index href", "href", href, "folder", folder)
+		switch folder {
+		case constants.OutputHtmlRanking_ByCourseFolder:
 			htmlRanking.ByCourse = pages
-		} else if strings.HasSuffix(href, constants.HtmlRankingUrl_IndexSuffix_ById) {
-			slog.Debug("pattern matched index href with ById", "href", href)
+		case constants.OutputHtmlRanking_ByIdFolder:
 			htmlRanking.ById = pages
-		} else if strings.HasSuffix(href, constants.HtmlRankingUrl_IndexSuffix_ByMerit) {
-			slog.Debug("pattern matched index href with ByMerit", "href", href)
+		case constants.OutputHtmlRanking_ByMeritFolder:
 			htmlRanking.ByMerit = pages
-		} else {
-			slog.Error("Index not recognized, please investigate.", "index_href", href, "index_url", link)
-			continue
 		}
 
 		count += len(pages)
@@ -189,6 +123,105 @@ func ScrapeRecursiveRankingHtmls(startingLink string) HtmlRanking {
 	return htmlRanking
 }
 
+// RankingIndexLinks returns the hrefs of the sub-indexes linked by a ranking index page
+func RankingIndexLinks(doc *goquery.Document) []string {
+	hrefs := make([]string, 0)
+	doc.Find(".titolo a").Each(func(_ int, s *goquery.Selection) {
+		href, _ := s.Attr("href")
+		hrefs = append(hrefs, href)
+	})
+	return hrefs
+}
+
+// RankingTableLinks returns the hrefs of the table pages linked by a ranking sub-index page
+func RankingTableLinks(doc *goquery.Document) []string {
+	hrefs := make([]string, 0)
+	doc.Find(".TableDati td a").Each(func(_ int, s *goquery.Selection) {
+		href, _ := s.Attr("href")
+		hrefs = append(hrefs, href)
+	})
+	return hrefs
+}
+
+// RankingIndexFolder returns the table folder (by_merit, by_id, by_course) of a sub-index href
+func RankingIndexFolder(href string) (string, bool) {
+	// IMPORTANT!!
+	// ByCourse MUST BE THE FIRST CASE
+	// otherwise it will match ByMerit also for ByCourse Index
+	switch {
+	case strings.HasSuffix(href, constants.HtmlRankingUrl_IndexSuffix_ByCourse):
+		return constants.OutputHtmlRanking_ByCourseFolder, true
+	case strings.HasSuffix(href, constants.HtmlRankingUrl_IndexSuffix_ById):
+		return constants.OutputHtmlRanking_ByIdFolder, true
+	case strings.HasSuffix(href, constants.HtmlRankingUrl_IndexSuffix_ByMerit):
+		return constants.OutputHtmlRanking_ByMeritFolder, true
+	default:
+		return "", false
+	}
+}
+
+// RankingSubIndexFilename is the name of the saved sub-index page of a table folder, next to index.html
+func RankingSubIndexFilename(folder string) string {
+	return constants.OutputHtmlRanking_SubIndexPrefix + folder + ".html"
+}
+
+// Save writes the ranking html pages in outDir/<id>, following the
+// index.html, by_merit, by_id, by_course layout
+func (r *HtmlRanking) Save(outDir string) error {
+	root := path.Join(outDir, r.Id) // path of this ranking's html root folder
+	w := writer.NewWriter[[]byte](root)
+
+	if err := w.Write(constants.OutputHtmlRanking_IndexFilename, r.Index.Content); err != nil {
+		return fmt.Errorf("could not save ranking index html: %w", err)
+	}
+
+	for _, index := range r.Indexes {
+		if err := w.Write(RankingSubIndexFilename(index.Id), index.Content); err != nil {
+			return fmt.Errorf("could not save ranking %s sub-index html: %w", index.Id, err)
+		}
+	}
+
+	folders := []struct {
+		name  string
+		pages []HtmlPage
+	}{
+		{constants.OutputHtmlRanking_ByMeritFolder, r.ByMerit},
+		{constants.OutputHtmlRanking_ByIdFolder, r.ById},
+		{constants.OutputHtmlRanking_ByCourseFolder, r.ByCourse},
+	}
+
+	for _, folder := range folders {
+		// update writer outDir path to the table folder
+		if err := w.ChangeDirPath(path.Join(root, folder.name)); err != nil {
+			return err
+		}
+
+		for _, page := range folder.pages {
+			if err := w.Write(page.Id, page.Content); err != nil {
+				return fmt.Errorf("could not save ranking %s table html, page %s: %w", folder.name, page.Id, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// RankingIdFromLink returns the id of the ranking (e.g. 2024_20103_2d5d_html),
+// which is the first segment of the link path
+func RankingIdFromLink(link string) (string, error) {
+	u, err := url.Parse(link)
+	if err != nil {
+		return "", err
+	}
+
+	splitted := strings.Split(u.Path, "/")
+	if len(splitted) < 2 || splitted[1] == "" {
+		return "", fmt.Errorf("could not find ranking id in link %s", link)
+	}
+
+	return splitted[1], nil
+}
+
 func (r *HtmlRanking) Debug() {
-	slog.Debug("HtmlRanking", "id", r.Id, "byCourse", len(r.ByCourse), "byMerit", len(r.ByMerit), "byId", len(r.ById))
+	slog.Debug("HtmlRanking", "id", r.Id, "indexes", len(r.Indexes), "byCourse", len(r.ByCourse), "byMerit", len(r.ByMerit), "byId", len(r.ById))
 }
@@ -0,0 +1,64 @@
+package scraper
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path"
+
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/utils"
+)
+
+// StudyPlanFilename is the name of the saved manifesto page (and of the parsed study plan, with another ext).
+// A course has a manifesto for each academic year, with the same url in different snapshots.
+func (m *Manifesto) StudyPlanFilename(ext string) string {
+	return fmt.Sprintf("%d_%d%s", m.CourseCode(), m.Year, ext)
+}
+
+// saveStudyPlanPage writes the html page of the manifesto in absHtmlDir
+func saveStudyPlanPage(absHtmlDir string, m Manifesto, data []byte) error {
+	fn := m.StudyPlanFilename(".html")
+	if err := os.WriteFile(path.Join(absHtmlDir, fn), data, 0o664); err != nil {
+		return fmt.Errorf("could not save study plan %s: %w", fn, err)
+	}
+	return nil
+}
+
+// DownloadStudyPlans saves the html page of the manifesti not saved yet in absHtmlDir.
+// ScrapeManifesti already saves the pages it loads, so this only downloads the pages
+// of manifesti scraped by previous runs (or by older versions).
+// Returns the number of downloaded pages.
+func DownloadStudyPlans(mans []Manifesto, absHtmlDir string) (int, error) {
+	if err := utils.CreateFolderIfNotExists(absHtmlDir); err != nil {
+		return 0, err
+	}
+
+	// one page per course and academic year, shared by every location
+	seen := map[string]bool{}
+	downloaded := 0
+	for _, m := range mans {
+		fn := m.StudyPlanFilename(".html")
+		if seen[fn] {
+			continue
+		}
+		seen[fn] = true
+
+		if _, err := os.Stat(path.Join(absHtmlDir, fn)); err == nil {
+			slog.Debug("study plan already saved, skipping...", "filename", fn)
+			continue
+		}
+
+		_, _, data, err := utils.LoadHttpHtml(m.Url)
+		if err != nil {
+			slog.Error("could not download study plan", "url", m.Url, "error", err)
+			continue
+		}
+
+		if err := saveStudyPlanPage(absHtmlDir, m, data); err != nil {
+			return downloaded, err
+		}
+		downloaded++
+	}
+
+	return downloaded, nil
+}
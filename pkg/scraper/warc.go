@@ -0,0 +1,41 @@
+package scraper
+
+import (
+	"bytes"
+	"log/slog"
+	"maps"
+	"net/url"
+	"slices"
+
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/utils"
+	"github.com/PuerkitoBio/goquery"
+)
+
+// ArchivedRankingLinks returns the main page of every ranking archived in the WARC responses:
+// a page of the rankings domain linking (.titolo a) to the sub-indexes of the ranking
+func ArchivedRankingLinks(responses map[string]*utils.WarcResponse, rankingsDomain string) []string {
+	links := []string{}
+	for _, rawUrl := range slices.Sorted(maps.Keys(responses)) {
+		res := responses[rawUrl]
+		u, err := url.Parse(rawUrl)
+		if err != nil || u.Host != rankingsDomain || res.StatusCode != 200 || rawUrl != res.RequestedUrl {
+			continue // redirect targets are archived also by the requested url
+		}
+
+		doc, err := goquery.NewDocumentFromReader(bytes.NewReader(res.Body))
+		if err != nil {
+			slog.Warn("[warc] could not parse archived page", "url", rawUrl, "error", err)
+			continue
+		}
+
+		isRankingIndex := slices.ContainsFunc(RankingIndexLinks(doc), func(href string) bool {
+			_, ok := RankingIndexFolder(href)
+			return ok
+		})
+		if isRankingIndex {
+			links = append(links, rawUrl)
+		}
+	}
+
+	return links
+}
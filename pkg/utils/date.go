@@ -0,0 +1,53 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const DateLayout = "2006-01-02"
+
+// layouts used by Polimi tables, the first one is the most common
+var tableDateLayouts = []string{"02/01/2006", "2/1/2006", "02-01-2006", DateLayout}
+
+// Date is a calendar date without time, encoded in json as ISO 8601 (YYYY-MM-DD)
+type Date struct {
+	time.Time
+}
+
+// ParseTableDate parses a date as written in Polimi tables (e.g. 31/12/2005)
+func ParseTableDate(raw string) (Date, error) {
+	raw = strings.TrimSpace(raw)
+	for _, layout := range tableDateLayouts {
+		if t, err := time.Parse(layout, raw); err == nil {
+			return Date{t}, nil
+		}
+	}
+
+	return Date{}, fmt.Errorf("could not parse date '%s'", raw)
+}
+
+func (d Date) String() string {
+	return d.Format(DateLayout)
+}
+
+func (d Date) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+func (d *Date) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	t, err := time.Parse(DateLayout, s)
+	if err != nil {
+		return fmt.Errorf("invalid date '%s', expected format YYYY-MM-DD", s)
+	}
+
+	d.Time = t
+	return nil
+}
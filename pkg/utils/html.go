@@ -3,7 +3,6 @@ package utils
 import (
 	"bytes"
 	"fmt"
-	"io"
 	"html"
 	"log/slog"
 	"net/http"
@@ -14,28 +13,15 @@ import (
 )
 
 func LoadHttpHtml(url string) (*goquery.Document, *http.Response, []byte, error) {
-	client := &http.Client{}
-	req, err := http.NewRequest(http.MethodGet, url, nil)
+	res, htmlBytes, err := cachedGet(url)
 	if err != nil {
 		return nil, nil, nil, err
 	}
 
-	req.Header.Set("User-Agent", "Mozilla/5.0")
-	res, err := client.Do(req)
-	if err != nil {
-		return nil, nil, nil, err
-	}
-
-	defer res.Body.Close()
 	if res.StatusCode != 200 {
 		return nil, nil, nil, fmt.Errorf("HTTP code is not 200. Status: %s", res.Status)
 	}
 
-	htmlBytes, err := io.ReadAll(res.Body)
-	if err != nil {
-		return nil, nil, nil, err
-	}
-
 	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(htmlBytes))
 	if err != nil {
 		return nil, nil, nil, err
@@ -0,0 +1,253 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+)
+
+// HttpCacheRule sets the TTL of the urls matching Pattern (a regexp)
+type HttpCacheRule struct {
+	Pattern string
+	TTL     time.Duration
+}
+
+type compiledCacheRule struct {
+	re  *regexp.Regexp
+	ttl time.Duration
+}
+
+// httpCacheEntry is the metadata of a cached response, its body is saved next to it
+type httpCacheEntry struct {
+	Url          string    `json:"url"`
+	FinalUrl     string    `json:"finalUrl"` // after redirects
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"lastModified,omitempty"`
+	ContentType  string    `json:"contentType,omitempty"`
+	FetchedAt    time.Time `json:"fetchedAt"`   // first download of this body
+	ValidatedAt  time.Time `json:"validatedAt"` // last time the server confirmed it
+}
+
+var (
+	httpCacheDir   string // empty = cache disabled
+	httpCacheRules []compiledCacheRule
+)
+
+// compileCacheRules skips (and logs) invalid patterns, the config validation should have caught them
+func compileCacheRules(rules []HttpCacheRule) []compiledCacheRule {
+	compiled := make([]compiledCacheRule, 0, len(rules))
+	for _, r := range rules {
+		re, err := regexp.Compile(r.Pattern)
+		if err != nil {
+			slog.Error("[http-cache] invalid url pattern, skipping rule", "pattern", r.Pattern, "error", err)
+			continue
+		}
+		compiled = append(compiled, compiledCacheRule{re: re, ttl: r.TTL})
+	}
+	return compiled
+}
+
+// cacheTTL returns the TTL of the first rule matching the url, or the default one
+func cacheTTL(rawUrl string) time.Duration {
+	for _, r := range httpCacheRules {
+		if r.re.MatchString(rawUrl) {
+			return r.ttl
+		}
+	}
+	return httpOptions.CacheTTL
+}
+
+func cachePaths(rawUrl string) (string, string) {
+	sum := sha256.Sum256([]byte(rawUrl))
+	name := hex.EncodeToString(sum[:])
+	base := filepath.Join(httpCacheDir, name[:2], name)
+	return base + ".json", base + ".body"
+}
+
+func readCacheEntry(rawUrl string) (*httpCacheEntry, []byte, bool) {
+	metaPath, bodyPath := cachePaths(rawUrl)
+	meta, err := os.ReadFile(metaPath)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			slog.Warn("[http-cache] could not read cache entry", "url", rawUrl, "error", err)
+		}
+		return nil, nil, false
+	}
+
+	entry := &httpCacheEntry{}
+	if err := json.Unmarshal(meta, entry); err != nil || entry.Url != rawUrl {
+		slog.Warn("[http-cache] invalid cache entry, ignoring it", "url", rawUrl, "error", err)
+		return nil, nil, false
+	}
+
+	body, err := os.ReadFile(bodyPath)
+	if err != nil {
+		slog.Warn("[http-cache] cache entry without body, ignoring it", "url", rawUrl, "error", err)
+		return nil, nil, false
+	}
+	return entry, body, true
+}
+
+// writeCacheEntry writes body and metadata through temp files, so a crash never leaves half an entry
+func writeCacheEntry(entry *httpCacheEntry, body []byte) {
+	metaPath, bodyPath := cachePaths(entry.Url)
+	if err := os.MkdirAll(filepath.Dir(metaPath), os.ModePerm); err != nil {
+		slog.Warn("[http-cache] could not create cache folder", "error", err)
+		return
+	}
+
+	meta, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+
+	if body != nil {
+		if err := writeFileAtomic(bodyPath, body); err != nil {
+			slog.Warn("[http-cache] could not write cache body", "url", entry.Url, "error", err)
+			return
+		}
+	}
+	if err := writeFileAtomic(metaPath, meta); err != nil {
+		slog.Warn("[http-cache] could not write cache entry", "url", entry.Url, "error", err)
+	}
+}
+
+func writeFileAtomic(p string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(p), filepath.Base(p)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), p)
+}
+
+// cachedResponse rebuilds the response of a cache entry, with the final url as request url (used for relative links)
+func cachedResponse(entry *httpCacheEntry) *http.Response {
+	finalUrl, err := url.Parse(entry.FinalUrl)
+	if err != nil {
+		finalUrl, _ = url.Parse(entry.Url)
+	}
+
+	header := http.Header{}
+	if entry.ContentType != "" {
+		header.Set("Content-Type", entry.ContentType)
+	}
+	return &http.Response{
+		Status:     "200 OK",
+		StatusCode: http.StatusOK,
+		Header:     header,
+		Body:       http.NoBody,
+		Request:    &http.Request{Method: http.MethodGet, URL: finalUrl},
+	}
+}
+
+func (h *hostState) cacheHit(revalidated bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if revalidated {
+		h.metrics.CacheRevalidated++
+	} else {
+		h.metrics.CacheHits++
+	}
+}
+
+// cachedGet is a GET through the on-disk cache: a fresh entry is returned without any request,
+// an expired one is revalidated with a conditional GET (If-None-Match / If-Modified-Since).
+// Only 200 responses are cached.
+// With the WARC archive on, the cached entries are not used (only refreshed): every page is
+// downloaded and archived as a full response, so the archive can be replayed on its own.
+func cachedGet(rawUrl string) (*http.Response, []byte, error) {
+	if httpReplay != nil {
+		return replayGet(rawUrl)
+	}
+	if httpCacheDir == "" {
+		return getAndRead(rawUrl, nil)
+	}
+
+	u, err := url.Parse(rawUrl)
+	if err != nil {
+		return nil, nil, err
+	}
+	h := getHostState(u.Host)
+
+	entry, body, ok := readCacheEntry(rawUrl)
+	if warcWriter != nil {
+		ok = false
+	}
+	if ok && time.Since(entry.ValidatedAt) < cacheTTL(rawUrl) {
+		h.cacheHit(false)
+		slog.Debug("[http-cache] fresh", "url", rawUrl, "validatedAt", entry.ValidatedAt)
+		return cachedResponse(entry), body, nil
+	}
+
+	header := http.Header{}
+	if ok && entry.ETag != "" {
+		header.Set("If-None-Match", entry.ETag)
+	}
+	if ok && entry.LastModified != "" {
+		header.Set("If-Modified-Since", entry.LastModified)
+	}
+
+	res, data, err := getAndRead(rawUrl, header)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if ok && res.StatusCode == http.StatusNotModified {
+		h.cacheHit(true)
+		slog.Debug("[http-cache] not modified", "url", rawUrl)
+		entry.ValidatedAt = time.Now()
+		writeCacheEntry(entry, nil)
+		return cachedResponse(entry), body, nil
+	}
+
+	if res.StatusCode == http.StatusOK {
+		now := time.Now()
+		writeCacheEntry(&httpCacheEntry{
+			Url:          rawUrl,
+			FinalUrl:     res.Request.URL.String(),
+			ETag:         res.Header.Get("ETag"),
+			LastModified: res.Header.Get("Last-Modified"),
+			ContentType:  res.Header.Get("Content-Type"),
+			FetchedAt:    now,
+			ValidatedAt:  now,
+		}, data)
+	}
+	return res, data, nil
+}
+
+func getAndRead(rawUrl string, header http.Header) (*http.Response, []byte, error) {
+	res, err := HttpDo(httpClient, http.MethodGet, rawUrl, header, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer res.Body.Close()
+
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if res.StatusCode != http.StatusNotModified {
+		archiveResponse(rawUrl, res, data)
+	}
+	return res, data, nil
+}
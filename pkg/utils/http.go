@@ -1,10 +1,11 @@
 package utils
 
 import (
-	"context"
+	"errors"
 	"log/slog"
 	"net/http"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -14,11 +15,14 @@ type HeadResult struct {
 	Err        error
 }
 
+// HttpHeadAll sends a HEAD request to every link. Once the budget is exceeded the remaining
+// links are not requested, their result has ErrHttpBudgetExceeded.
 func HttpHeadAll(
 	links []string,
 	maxWorkers int, // number of concurrent HTTP requests
 	rps int, // requests per second (0 = unlimited)
 	reqTimeout time.Duration, // per-request timeout
+	budget *HttpBudget, // nil = per-host budget
 ) []HeadResult {
 	n := len(links)
 	results := make([]HeadResult, n)
@@ -51,37 +55,40 @@ func HttpHeadAll(
 		tickCh = ticker.C
 	}
 
+	// set by the first request over budget, the remaining jobs are not requested
+	budgetExceeded := atomic.Bool{}
+
 	// Start worker goroutines
 	for w := range maxWorkers {
 		go func(workerID int) {
 			for idx := range jobs {
-				// rate limit if requested
-				if tickCh != nil {
-					<-tickCh
-				}
-
 				link := links[idx]
 				result := HeadResult{Link: link, Err: nil}
 
-				ctx, cancel := context.WithTimeout(context.Background(), reqTimeout)
-				req, err := http.NewRequestWithContext(ctx, "HEAD", link, nil)
-				if err != nil {
-					result.Err = err
-					result.StatusCode = 500
+				if budgetExceeded.Load() {
+					result.Err = ErrHttpBudgetExceeded
 					results[idx] = result
-					slog.Error("[HTTP_HEAD] link error", "idx", idx, "link", link)
-					cancel()
 					wg.Done()
 					continue
 				}
 
-				resp, err := client.Do(req)
+				// rate limit if requested
+				if tickCh != nil {
+					<-tickCh
+				}
+
+				resp, err := HttpDoBudget(client, http.MethodHead, link, nil, reqTimeout, budget)
 				if err != nil {
 					result.Err = err
 					result.StatusCode = 500
 					results[idx] = result
-					slog.Error("[HTTP_HEAD] link error", "idx", idx, "link", link)
-					cancel()
+					if errors.Is(err, ErrHttpBudgetExceeded) {
+						if !budgetExceeded.Swap(true) {
+							slog.Warn("[HTTP_HEAD] request budget exceeded, skipping the remaining links", "idx", idx, "link", link)
+						}
+					} else {
+						slog.Error("[HTTP_HEAD] link error", "idx", idx, "link", link, "error", err)
+					}
 					wg.Done()
 					continue
 				}
@@ -93,7 +100,7 @@ func HttpHeadAll(
 				} else {
 					slog.Info("[HTTP_HEAD] link not 200", "idx", idx, "link", link, "statusCode", resp.StatusCode)
 				}
-				cancel()
+				resp.Body.Close()
 				wg.Done()
 			}
 		}(w)
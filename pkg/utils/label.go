@@ -0,0 +1,52 @@
+package utils
+
+import (
+	"html"
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Polimi pages write the Italian text first and the English translation after a <br/>
+// (headings) or a " / " (table cells)
+var brRegex = regexp.MustCompile(`(?i)<br\s*/?>`)
+
+// Label is a text in both languages. En is empty if Polimi does not provide the translation.
+type Label struct {
+	It string `json:"it"`
+	En string `json:"en,omitempty"`
+}
+
+// GetTextFragments returns the Italian and English fragments of s, split on <br/>.
+// Unlike GetFirstTextFragment, tags inside the fragments are stripped and spaces are trimmed.
+func GetTextFragments(s *goquery.Selection) (Label, error) {
+	innerHtml, err := s.Html()
+	if err != nil {
+		return Label{}, err
+	}
+
+	fragments := brRegex.Split(innerHtml, 2)
+	label := Label{It: fragmentText(fragments[0])}
+	if len(fragments) > 1 {
+		label.En = fragmentText(fragments[1])
+	}
+
+	return label, nil
+}
+
+// SplitLabel splits a plain text as "<italian> / <english>"
+func SplitLabel(s string) Label {
+	it, en, _ := strings.Cut(s, " / ")
+	return Label{It: strings.TrimSpace(it), En: strings.TrimSpace(en)}
+}
+
+func fragmentText(fragment string) string {
+	fragment = brRegex.ReplaceAllString(fragment, " ") // e.g. a translation on two lines
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(fragment))
+	if err != nil {
+		return strings.TrimSpace(html.UnescapeString(fragment))
+	}
+
+	return strings.Join(strings.Fields(doc.Text()), " ")
+}
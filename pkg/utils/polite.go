@@ -0,0 +1,452 @@
+package utils
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/PoliNetworkOrg/rankings-backend-go/pkg/constants"
+)
+
+var (
+	ErrHttpBudgetExceeded = errors.New("request budget of the host exceeded for this run")
+	ErrRobotsDisallowed   = errors.New("url disallowed by robots.txt")
+)
+
+// HttpOptions configures the politeness layer shared by every HTTP request of the scraper
+type HttpOptions struct {
+	UserAgent   string
+	Timeout     time.Duration // timeout of LoadHttpHtml requests
+	MaxRetries  int           // retries after 429, 503 or a timeout
+	BackoffBase time.Duration // first backoff, doubled at each throttled response of the host
+	MaxBackoff  time.Duration // max backoff, and max Retry-After we are willing to wait
+	HostBudget  int           // max requests per host per run (HttpBudget requests excluded), 0 = unlimited
+	Robots      bool          // honour robots.txt
+
+	CacheDir   string          // on-disk cache of LoadHttpHtml, empty = disabled
+	CacheTTL   time.Duration   // a cached response younger than this is used without revalidation
+	CacheRules []HttpCacheRule // TTL by url pattern, the first matching wins
+}
+
+// HostMetrics counts the requests made to a host and how much it throttled us
+type HostMetrics struct {
+	Requests       int           `json:"requests"`
+	Retries        int           `json:"retries"`
+	Throttled      int           `json:"throttled"` // 429 and 503 responses
+	Timeouts       int           `json:"timeouts"`
+	RetryAfter     int           `json:"retryAfter"` // responses with a Retry-After header
+	Waited         time.Duration `json:"waited"`     // time spent waiting for the host
+	BudgetExceeded int           `json:"budgetExceeded"`
+	RobotsBlocked  int           `json:"robotsBlocked"`
+
+	CacheHits        int `json:"cacheHits"`        // fresh cached responses, no request made
+	CacheRevalidated int `json:"cacheRevalidated"` // 304 Not Modified
+}
+
+type hostState struct {
+	mu      sync.Mutex
+	delay   time.Duration // adaptive delay between two requests
+	next    time.Time     // no request before this time
+	metrics HostMetrics
+	// requests counted against an HttpBudget instead of the host budget
+	budgeted int
+
+	robotsOnce sync.Once
+	robots     *robotsRules
+}
+
+var (
+	httpOptions = HttpOptions{
+		UserAgent:   constants.HttpDefaultUserAgent,
+		Timeout:     30 * time.Second,
+		MaxRetries:  3,
+		BackoffBase: time.Second,
+		MaxBackoff:  time.Minute,
+	}
+	httpClient = &http.Client{Timeout: httpOptions.Timeout}
+
+	hostsMu sync.Mutex
+	hosts   = map[string]*hostState{}
+)
+
+// ConfigureHttp sets the options of the politeness layer and resets its per-host state
+func ConfigureHttp(opts HttpOptions) {
+	httpOptions = opts
+	httpClient = &http.Client{Timeout: opts.Timeout}
+	httpCacheDir = opts.CacheDir
+	httpCacheRules = compileCacheRules(opts.CacheRules)
+
+	hostsMu.Lock()
+	hosts = map[string]*hostState{}
+	hostsMu.Unlock()
+}
+
+// HttpBudget is the request budget of a job (e.g. the bruteforce), its requests
+// are not counted against the per-host budget of the other requests
+type HttpBudget struct {
+	mu   sync.Mutex
+	max  int // 0 = unlimited
+	used int
+}
+
+func NewHttpBudget(max int) *HttpBudget {
+	return &HttpBudget{max: max}
+}
+
+func (b *HttpBudget) take() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.max > 0 && b.used >= b.max {
+		return false
+	}
+	b.used++
+	return true
+}
+
+func getHostState(host string) *hostState {
+	hostsMu.Lock()
+	defer hostsMu.Unlock()
+
+	h, ok := hosts[host]
+	if !ok {
+		h = &hostState{}
+		hosts[host] = h
+	}
+	return h
+}
+
+// HttpDo sends a request through the politeness layer: robots.txt, per-host budget,
+// adaptive delay between requests, and retries honouring Retry-After on 429/503/timeouts.
+// If timeout > 0 it is applied to each attempt, the body must be closed to release it.
+func HttpDo(client *http.Client, method, rawUrl string, header http.Header, timeout time.Duration) (*http.Response, error) {
+	return HttpDoBudget(client, method, rawUrl, header, timeout, nil)
+}
+
+// HttpDoBudget is HttpDo counting the request against budget instead of the per-host budget, if not nil
+func HttpDoBudget(client *http.Client, method, rawUrl string, header http.Header, timeout time.Duration, budget *HttpBudget) (*http.Response, error) {
+	u, err := url.Parse(rawUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	h := getHostState(u.Host)
+	if httpOptions.Robots && !h.allowed(client, u) {
+		h.mu.Lock()
+		h.metrics.RobotsBlocked++
+		h.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s", ErrRobotsDisallowed, rawUrl)
+	}
+
+	for attempt := 0; ; attempt++ {
+		if err := h.wait(attempt > 0, budget); err != nil {
+			return nil, fmt.Errorf("%w: %s", err, u.Host)
+		}
+
+		res, err := doOnce(client, method, rawUrl, header, timeout)
+		throttled := err == nil && (res.StatusCode == http.StatusTooManyRequests || res.StatusCode == http.StatusServiceUnavailable)
+		timedOut := err != nil && isTimeout(err)
+		if !throttled && !timedOut {
+			if err == nil {
+				h.success()
+			}
+			return res, err
+		}
+
+		retryAfter := time.Duration(0)
+		if throttled {
+			retryAfter = parseRetryAfter(res.Header.Get("Retry-After"))
+			res.Body.Close()
+		}
+
+		// a longer Retry-After makes us give up, the host is paused for MaxBackoff at most
+		wait := h.throttle(throttled, min(retryAfter, httpOptions.MaxBackoff))
+		if attempt >= httpOptions.MaxRetries || retryAfter > httpOptions.MaxBackoff {
+			slog.Warn("[http] giving up, the host keeps throttling us", "url", rawUrl, "attempts", attempt+1, "retryAfter", retryAfter)
+			if throttled {
+				return nil, fmt.Errorf("%s %s: throttled (HTTP %d) after %d attempts", method, rawUrl, res.StatusCode, attempt+1)
+			}
+			return nil, err
+		}
+
+		slog.Debug("[http] throttled, retrying", "url", rawUrl, "attempt", attempt+1, "wait", wait, "timeout", timedOut)
+	}
+}
+
+// HttpGet sends a GET request through the politeness layer, with the configured timeout
+func HttpGet(rawUrl string) (*http.Response, error) {
+	return HttpDo(httpClient, http.MethodGet, rawUrl, nil, 0)
+}
+
+func doOnce(client *http.Client, method, rawUrl string, header http.Header, timeout time.Duration) (*http.Response, error) {
+	ctx, cancel := context.Background(), context.CancelFunc(func() {})
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, rawUrl, nil)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.Header.Set("User-Agent", httpOptions.UserAgent)
+
+	res, err := client.Do(req)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	res.Body = &cancelOnClose{ReadCloser: res.Body, cancel: cancel}
+	return res, nil
+}
+
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnClose) Close() error {
+	defer c.cancel()
+	return c.ReadCloser.Close()
+}
+
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout())
+}
+
+// parseRetryAfter reads a Retry-After header, in seconds or as an HTTP date
+func parseRetryAfter(v string) time.Duration {
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(strings.TrimSpace(v)); err == nil {
+		return time.Duration(max(secs, 0)) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return max(time.Until(t), 0)
+	}
+	return 0
+}
+
+// wait takes a request of the budget (the host one if budget is nil) and waits for the turn of the request
+func (h *hostState) wait(retry bool, budget *HttpBudget) error {
+	h.mu.Lock()
+	exceeded := false
+	if budget != nil {
+		exceeded = !budget.take()
+	} else {
+		exceeded = httpOptions.HostBudget > 0 && h.metrics.Requests-h.budgeted >= httpOptions.HostBudget
+	}
+	if exceeded {
+		h.metrics.BudgetExceeded++
+		h.mu.Unlock()
+		return ErrHttpBudgetExceeded
+	}
+
+	h.metrics.Requests++
+	if budget != nil {
+		h.budgeted++
+	}
+	if retry {
+		h.metrics.Retries++
+	}
+
+	now := time.Now()
+	at := h.next
+	if at.Before(now) {
+		at = now
+	}
+	h.next = at.Add(h.delay)
+	wait := at.Sub(now)
+	h.metrics.Waited += wait
+	h.mu.Unlock()
+
+	time.Sleep(wait)
+	return nil
+}
+
+// throttle doubles the delay of the host and pauses it, returns the pause
+func (h *hostState) throttle(throttled bool, retryAfter time.Duration) time.Duration {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if throttled {
+		h.metrics.Throttled++
+	} else {
+		h.metrics.Timeouts++
+	}
+	if retryAfter > 0 {
+		h.metrics.RetryAfter++
+	}
+
+	h.delay = min(max(h.delay*2, httpOptions.BackoffBase), httpOptions.MaxBackoff)
+	pause := max(h.delay, retryAfter)
+	if next := time.Now().Add(pause); next.After(h.next) {
+		h.next = next
+	}
+	return pause
+}
+
+// success halves the delay of the host, down to no delay
+func (h *hostState) success() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.delay /= 2
+	if h.delay < 10*time.Millisecond {
+		h.delay = 0
+	}
+}
+
+// HttpMetrics returns the metrics of every host contacted in this run
+func HttpMetrics() map[string]HostMetrics {
+	hostsMu.Lock()
+	defer hostsMu.Unlock()
+
+	metrics := make(map[string]HostMetrics, len(hosts))
+	for host, h := range hosts {
+		h.mu.Lock()
+		metrics[host] = h.metrics
+		h.mu.Unlock()
+	}
+	return metrics
+}
+
+func LogHttpMetrics() {
+	metrics := HttpMetrics()
+	for _, host := range slices.Sorted(maps.Keys(metrics)) {
+		m := metrics[host]
+		slog.Info("[http] host metrics", "host", host, "requests", m.Requests, "retries", m.Retries, "throttled", m.Throttled,
+			"timeouts", m.Timeouts, "retryAfter", m.RetryAfter, "waited", m.Waited, "budgetExceeded", m.BudgetExceeded, "robotsBlocked", m.RobotsBlocked,
+			"cacheHits", m.CacheHits, "cacheRevalidated", m.CacheRevalidated)
+	}
+}
+
+// robotsRules are the Allow/Disallow rules of robots.txt applying to our User-Agent
+type robotsRules struct {
+	allow    []*regexp.Regexp
+	disallow []*regexp.Regexp
+	patterns map[*regexp.Regexp]int // pattern length, the longest matching rule wins
+}
+
+func (h *hostState) allowed(client *http.Client, u *url.URL) bool {
+	h.robotsOnce.Do(func() {
+		h.robots = fetchRobots(client, u)
+	})
+	return h.robots.allows(u.RequestURI())
+}
+
+// fetchRobots downloads robots.txt of the host, a missing or unreadable robots.txt allows everything
+func fetchRobots(client *http.Client, u *url.URL) *robotsRules {
+	robotsUrl := u.Scheme + "://" + u.Host + "/robots.txt"
+	res, err := doOnce(client, http.MethodGet, robotsUrl, nil, httpOptions.Timeout)
+	if err != nil {
+		slog.Debug("[http] could not load robots.txt, allowing everything", "url", robotsUrl, "error", err)
+		return nil
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil
+	}
+	return parseRobots(res.Body, httpOptions.UserAgent)
+}
+
+// parseRobots keeps the rules of the groups of our User-Agent, or of the * groups if none matches
+func parseRobots(r io.Reader, userAgent string) *robotsRules {
+	ours, wildcard := &robotsRules{patterns: map[*regexp.Regexp]int{}}, &robotsRules{patterns: map[*regexp.Regexp]int{}}
+	product := strings.ToLower(strings.SplitN(userAgent, "/", 2)[0])
+	matchesOurs, matchesAny, inAgents := false, false, false
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key, value = strings.ToLower(strings.TrimSpace(key)), strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			if !inAgents {
+				matchesOurs, matchesAny = false, false
+			}
+			inAgents = true
+			agent := strings.ToLower(value)
+			matchesOurs = matchesOurs || (agent != "*" && product != "" && strings.Contains(product, agent))
+			matchesAny = matchesAny || agent == "*"
+		case "allow", "disallow":
+			inAgents = false
+			if value == "" {
+				continue // empty disallow allows everything
+			}
+			for _, rules := range []*robotsRules{ours, wildcard} {
+				if (rules == ours && matchesOurs) || (rules == wildcard && matchesAny) {
+					rules.add(key == "allow", value)
+				}
+			}
+		default:
+			inAgents = false
+		}
+	}
+
+	if len(ours.allow)+len(ours.disallow) > 0 {
+		return ours
+	}
+	return wildcard
+}
+
+func (r *robotsRules) add(allow bool, pattern string) {
+	expr := "^" + strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, ".*")
+	if strings.HasSuffix(expr, `\$`) {
+		expr = strings.TrimSuffix(expr, `\$`) + "$"
+	}
+
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return
+	}
+	r.patterns[re] = len(pattern)
+	if allow {
+		r.allow = append(r.allow, re)
+	} else {
+		r.disallow = append(r.disallow, re)
+	}
+}
+
+func (r *robotsRules) allows(path string) bool {
+	if r == nil {
+		return true
+	}
+
+	longest := func(rules []*regexp.Regexp) int {
+		l := -1
+		for _, re := range rules {
+			if re.MatchString(path) {
+				l = max(l, r.patterns[re])
+			}
+		}
+		return l
+	}
+
+	return longest(r.allow) >= longest(r.disallow)
+}
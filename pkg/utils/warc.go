@@ -0,0 +1,374 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"net/http"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	warcVersion     = "WARC/1.0"
+	warcMaxFileSize = 1 << 30 // a new file is started after 1 GiB
+	warcExt         = ".warc.gz"
+	// not a standard WARC field: the url we asked for, when the response is the target of a redirect
+	warcRequestedUriField = "X-Requested-URI"
+)
+
+// WarcWriter appends every fetched response to gzipped WARC files (one gzip member per record)
+type WarcWriter struct {
+	mu      sync.Mutex
+	dir     string
+	prefix  string
+	file    *os.File
+	size    int64
+	fileNum int
+	records int
+}
+
+// NewWarcWriter creates the first WARC file in absDir, named <prefix>-<start time>-<n>.warc.gz
+func NewWarcWriter(absDir, prefix string) (*WarcWriter, error) {
+	if err := CreateFolderIfNotExists(absDir); err != nil {
+		return nil, err
+	}
+
+	w := &WarcWriter{dir: absDir, prefix: fmt.Sprintf("%s-%s", prefix, time.Now().UTC().Format("20060102T150405Z"))}
+	if err := w.rotate(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *WarcWriter) rotate() error {
+	if w.file != nil {
+		if err := w.file.Close(); err != nil {
+			return err
+		}
+	}
+
+	name := fmt.Sprintf("%s-%05d%s", w.prefix, w.fileNum, warcExt)
+	f, err := os.OpenFile(filepath.Join(w.dir, name), os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0o644)
+	if err != nil {
+		return fmt.Errorf("could not create WARC file %s: %w", name, err)
+	}
+
+	w.file, w.size = f, 0
+	w.fileNum++
+
+	info := fmt.Sprintf("software: rankings-backend-go\r\nformat: WARC File Format 1.0\r\nhttp-header-user-agent: %s\r\nrobots: %s\r\n",
+		httpOptions.UserAgent, map[bool]string{true: "obey", false: "ignore"}[httpOptions.Robots])
+	return w.writeRecord(textproto.MIMEHeader{
+		"Warc-Type":     {"warcinfo"},
+		"Warc-Filename": {name},
+		"Content-Type":  {"application/warc-fields"},
+	}, []byte(info), time.Now())
+}
+
+// writeRecord writes a record as a single gzip member, so a crash never corrupts the previous ones
+func (w *WarcWriter) writeRecord(fields textproto.MIMEHeader, block []byte, date time.Time) error {
+	id, err := newRecordId()
+	if err != nil {
+		return err
+	}
+
+	head := bytes.Buffer{}
+	head.WriteString(warcVersion + "\r\n")
+	fmt.Fprintf(&head, "WARC-Type: %s\r\n", fields.Get("Warc-Type"))
+	fmt.Fprintf(&head, "WARC-Record-ID: %s\r\n", id)
+	fmt.Fprintf(&head, "WARC-Date: %s\r\n", date.UTC().Format(time.RFC3339))
+	for _, k := range slices.Sorted(maps.Keys(fields)) {
+		if k == "Warc-Type" {
+			continue
+		}
+		for _, v := range fields[k] {
+			fmt.Fprintf(&head, "%s: %s\r\n", warcFieldName(k), v)
+		}
+	}
+	fmt.Fprintf(&head, "WARC-Block-Digest: %s\r\n", sha1Digest(block))
+	fmt.Fprintf(&head, "Content-Length: %d\r\n\r\n", len(block))
+
+	counter := &countingWriter{w: w.file}
+	gz := gzip.NewWriter(counter)
+	for _, part := range [][]byte{head.Bytes(), block, []byte("\r\n\r\n")} {
+		if _, err := gz.Write(part); err != nil {
+			return err
+		}
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+
+	w.size += counter.n
+	w.records++
+	return nil
+}
+
+// WriteResponse archives a response (requestedUrl is the url before redirects)
+func (w *WarcWriter) WriteResponse(requestedUrl string, res *http.Response, body []byte, date time.Time) error {
+	block := httpResponseHead(res)
+	block = append(block, body...)
+
+	fields := textproto.MIMEHeader{
+		"Warc-Type":           {"response"},
+		"Warc-Target-Uri":     {res.Request.URL.String()},
+		"Content-Type":        {"application/http; msgtype=response"},
+		"Warc-Payload-Digest": {sha1Digest(body)},
+	}
+	if requestedUrl != res.Request.URL.String() {
+		fields.Set(warcRequestedUriField, requestedUrl)
+	}
+	return w.write(fields, block, date)
+}
+
+func (w *WarcWriter) write(fields textproto.MIMEHeader, block []byte, date time.Time) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.size >= warcMaxFileSize {
+		if err := w.rotate(); err != nil {
+			return err
+		}
+	}
+	return w.writeRecord(fields, block, date)
+}
+
+func (w *WarcWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	slog.Info("[warc] archive closed", "dir", w.dir, "files", w.fileNum, "records", w.records)
+	return w.file.Close()
+}
+
+// httpResponseHead is the status line and the headers of a response, as received
+func httpResponseHead(res *http.Response) []byte {
+	head := bytes.Buffer{}
+	fmt.Fprintf(&head, "%s %s\r\n", res.Proto, res.Status)
+	res.Header.Write(&head)
+	head.WriteString("\r\n")
+	return head.Bytes()
+}
+
+// WARC field names are case insensitive, but the spec writes them as WARC-Xxx-Yyy
+func warcFieldName(canonical string) string {
+	name := strings.Replace(canonical, "Warc-", "WARC-", 1)
+	return strings.Replace(name, "-Uri", "-URI", 1)
+}
+
+func sha1Digest(data []byte) string {
+	sum := sha1.Sum(data)
+	return "sha1:" + base32.StdEncoding.EncodeToString(sum[:])
+}
+
+func newRecordId() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	b[6] = (b[6] & 0x0f) | 0x40 // uuid v4
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("<urn:uuid:%x-%x-%x-%x-%x>", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+var warcWriter *WarcWriter
+
+// ConfigureWarc archives every page fetched by LoadHttpHtml in w, nil disables the archive
+func ConfigureWarc(w *WarcWriter) {
+	warcWriter = w
+}
+
+func archiveResponse(requestedUrl string, res *http.Response, body []byte) {
+	if warcWriter == nil {
+		return
+	}
+	if err := warcWriter.WriteResponse(requestedUrl, res, body, time.Now()); err != nil {
+		slog.Error("[warc] could not archive response", "url", requestedUrl, "error", err)
+	}
+}
+
+// WarcResponse is a response read from a WARC file
+type WarcResponse struct {
+	Url          string // target url
+	RequestedUrl string // before redirects, same as Url if not redirected
+	Date         time.Time
+	StatusCode   int
+	Header       http.Header
+	Body         []byte
+}
+
+// ReadWarcResponses reads the response records of every WARC file of absDir (.warc or .warc.gz),
+// keeping the latest response of each url. Revisit records are skipped, their content
+// is the one of the previous response.
+func ReadWarcResponses(absDir string) (map[string]*WarcResponse, error) {
+	entries, err := GetEntriesInFolder(absDir)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := map[string]*WarcResponse{}
+	for _, e := range entries {
+		if e.IsDir() || !(strings.HasSuffix(e.Name(), ".warc") || strings.HasSuffix(e.Name(), warcExt)) {
+			continue
+		}
+
+		count, err := readWarcFile(filepath.Join(absDir, e.Name()), responses)
+		if err != nil {
+			return nil, fmt.Errorf("could not read WARC file %s: %w", e.Name(), err)
+		}
+		slog.Debug("[warc] read WARC file", "file", e.Name(), "responses", count)
+	}
+
+	return responses, nil
+}
+
+func readWarcFile(p string, responses map[string]*WarcResponse) (int, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(p, ".gz") {
+		gz, err := gzip.NewReader(f) // reads every gzip member
+		if err != nil {
+			return 0, err
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	br := bufio.NewReader(r)
+	tp := textproto.NewReader(br)
+	count := 0
+	for {
+		version, err := tp.ReadLine()
+		if errors.Is(err, io.EOF) {
+			return count, nil
+		}
+		if err != nil {
+			return count, err
+		}
+		if version == "" {
+			continue // record separators
+		}
+		if !strings.HasPrefix(version, "WARC/") {
+			return count, fmt.Errorf("expected WARC record, got %q", version)
+		}
+
+		fields, err := tp.ReadMIMEHeader()
+		if err != nil {
+			return count, err
+		}
+		length, err := strconv.ParseInt(fields.Get("Content-Length"), 10, 64)
+		if err != nil {
+			return count, fmt.Errorf("invalid Content-Length of record %s: %w", fields.Get("WARC-Record-ID"), err)
+		}
+		block := make([]byte, length)
+		if _, err := io.ReadFull(br, block); err != nil {
+			return count, err
+		}
+
+		if fields.Get("WARC-Type") != "response" || !strings.HasPrefix(fields.Get("Content-Type"), "application/http") {
+			continue
+		}
+
+		res, err := parseWarcResponse(fields, block)
+		if err != nil {
+			slog.Warn("[warc] skipping unreadable response record", "file", p, "record", fields.Get("WARC-Record-ID"), "error", err)
+			continue
+		}
+
+		for _, u := range []string{res.Url, res.RequestedUrl} {
+			if prev, ok := responses[u]; !ok || !res.Date.Before(prev.Date) {
+				responses[u] = res
+			}
+		}
+		count++
+	}
+}
+
+func parseWarcResponse(fields textproto.MIMEHeader, block []byte) (*WarcResponse, error) {
+	date, err := time.Parse(time.RFC3339, fields.Get("WARC-Date"))
+	if err != nil {
+		return nil, err
+	}
+
+	httpRes, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(block)), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer httpRes.Body.Close()
+
+	body, err := io.ReadAll(httpRes.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &WarcResponse{
+		Url:          fields.Get("WARC-Target-URI"),
+		RequestedUrl: fields.Get(warcRequestedUriField),
+		Date:         date,
+		StatusCode:   httpRes.StatusCode,
+		Header:       httpRes.Header,
+		Body:         body,
+	}
+	if res.RequestedUrl == "" {
+		res.RequestedUrl = res.Url
+	}
+	return res, nil
+}
+
+var httpReplay map[string]*WarcResponse
+
+// ConfigureHttpReplay makes LoadHttpHtml serve the pages from the responses of a WARC archive,
+// without any request: pages not archived are an error. nil goes back to the network.
+func ConfigureHttpReplay(responses map[string]*WarcResponse) {
+	httpReplay = responses
+}
+
+func replayGet(rawUrl string) (*http.Response, []byte, error) {
+	archived, ok := httpReplay[rawUrl]
+	if !ok {
+		return nil, nil, fmt.Errorf("%s is not archived", rawUrl)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, archived.Url, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &http.Response{
+		Status:     fmt.Sprintf("%d %s", archived.StatusCode, http.StatusText(archived.StatusCode)),
+		StatusCode: archived.StatusCode,
+		Header:     archived.Header,
+		Body:       http.NoBody,
+		Request:    req,
+	}, archived.Body, nil
+}